@@ -0,0 +1,462 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Finding describes an unusual wake pattern together with the evidence
+// (wake timestamps) that triggered it and the most likely cause
+type Finding struct {
+	Kind     string
+	Title    string
+	Evidence []time.Time
+	Cause    string
+}
+
+// Kinds of findings reported by detectWakeAnomalies
+const (
+	findingNewSource   = "new-source"
+	findingPeriodic    = "periodic"
+	findingShortSleeps = "short-sleeps"
+	findingSameMinute  = "same-minute"
+	// findingStandbyNetwork is derived from the configuration, not from wake events
+	findingStandbyNetwork = "standby-network"
+)
+
+const (
+	// periodicMinRepeats is the number of equal intervals needed before a timer is suspected
+	periodicMinRepeats = 3
+	// periodicMinInterval ignores intervals too short to be a deliberate timer
+	periodicMinInterval = 5 * time.Minute
+	// shortSleepLimit is the sleep duration below which a sleep counts as "short"
+	shortSleepLimit = time.Minute
+	// shortSleepBurstGap is the maximum distance between two short sleeps of one burst
+	shortSleepBurstGap = 30 * time.Minute
+	// shortSleepBurstSize is the number of short sleeps that make a burst
+	shortSleepBurstSize = 3
+	// sameMinuteMinDays is the number of different days with a wake at the same minute
+	sameMinuteMinDays = 3
+)
+
+// WakeBaseline records the wake sources accepted as normal on this machine. It is
+// stored in the SleepRight data directory and only changes through "SleepRight
+// baseline update", so a new wake source keeps being reported until it is accepted.
+type WakeBaseline struct {
+	Sources map[string]time.Time `json:"sources"` // wake source -> first seen
+}
+
+// wakeBaselinePath returns the path of the baseline file
+func wakeBaselinePath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "baseline.json"), nil
+}
+
+// loadWakeBaseline reads the baseline file; a missing file yields an empty baseline
+func loadWakeBaseline() (*WakeBaseline, error) {
+	baseline := &WakeBaseline{Sources: make(map[string]time.Time)}
+	path, err := wakeBaselinePath()
+	if err != nil {
+		return baseline, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return baseline, nil
+	}
+	if err != nil {
+		return baseline, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return baseline, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if baseline.Sources == nil {
+		baseline.Sources = make(map[string]time.Time)
+	}
+	return baseline, nil
+}
+
+// save writes the baseline file
+func (b *WakeBaseline) save() error {
+	path, err := wakeBaselinePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// learn adds all wake sources of the given events to the baseline
+func (b *WakeBaseline) learn(events []WakeEvent) {
+	for _, event := range events {
+		source := normalizeWakeSource(event.Source)
+		if first, known := b.Sources[source]; !known || event.Timestamp.Before(first) {
+			b.Sources[source] = event.Timestamp
+		}
+	}
+}
+
+// normalizeWakeSource makes wake sources comparable across runs
+func normalizeWakeSource(source string) string {
+	return strings.ToLower(strings.Join(strings.Fields(source), " "))
+}
+
+// detectWakeAnomalies compares wake events with the machine's baseline and returns
+// findings for new wake sources, periodic wakes, bursts of short sleeps and wakes
+// at the same minute on several days. The baseline may be nil or empty, in which
+// case new sources are not reported (nothing to compare against yet).
+func detectWakeAnomalies(events []WakeEvent, baseline *WakeBaseline) []Finding {
+	// Work on a copy sorted by wake time (oldest first)
+	sorted := make([]WakeEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var findings []Finding
+	findings = append(findings, detectNewWakeSources(sorted, baseline)...)
+	findings = append(findings, detectPeriodicWakes(sorted)...)
+	findings = append(findings, detectShortSleepBursts(sorted)...)
+	findings = append(findings, detectSameMinuteWakes(sorted)...)
+	return findings
+}
+
+// detectNewWakeSources reports wake sources that are not part of the baseline
+func detectNewWakeSources(events []WakeEvent, baseline *WakeBaseline) []Finding {
+	if baseline == nil || len(baseline.Sources) == 0 {
+		return nil
+	}
+
+	var order []string
+	evidence := make(map[string][]time.Time)
+	names := make(map[string]string)
+	for _, event := range events {
+		source := normalizeWakeSource(event.Source)
+		if _, known := baseline.Sources[source]; known {
+			continue
+		}
+		if _, seen := evidence[source]; !seen {
+			order = append(order, source)
+			names[source] = strings.TrimSpace(event.Source)
+		}
+		evidence[source] = append(evidence[source], event.Timestamp)
+	}
+
+	var findings []Finding
+	for _, source := range order {
+		findings = append(findings, Finding{
+			Kind:     findingNewSource,
+			Title:    fmt.Sprintf("Neue Aufweckquelle: %s", names[source]),
+			Evidence: evidence[source],
+			Cause:    "Ein bisher nicht beobachtetes Gerät oder ein neuer Zeitgeber weckt den PC (z.B. nach Treiber- oder Windows-Update).",
+		})
+	}
+	return findings
+}
+
+// detectPeriodicWakes reports repeated, equally long sleep durations (a timer armed
+// when the system goes to sleep) and repeated equal distances between wakes
+func detectPeriodicWakes(events []WakeEvent) []Finding {
+	var findings []Finding
+
+	var sleepDurations []time.Duration
+	var sleepStamps []time.Time
+	for _, event := range events {
+		if d := event.Timestamp.Sub(event.SleepTime); d >= periodicMinInterval {
+			sleepDurations = append(sleepDurations, d)
+			sleepStamps = append(sleepStamps, event.Timestamp)
+		}
+	}
+	for _, group := range findRepeatedDurations(sleepDurations, sleepStamps) {
+		findings = append(findings, Finding{
+			Kind:     findingPeriodic,
+			Title:    fmt.Sprintf("Aufwachen nach jeweils gleicher Schlafdauer (%s)", formatDuration(group.interval)),
+			Evidence: group.evidence,
+			Cause:    "Ein Zeitgeber wird beim Einschlafen gestellt (z.B. Ruhezustand-Timeout, Wartungs- oder Treiber-Timer).",
+		})
+	}
+
+	var gaps []time.Duration
+	var gapStamps []time.Time
+	for i := 1; i < len(events); i++ {
+		if d := events[i].Timestamp.Sub(events[i-1].Timestamp); d >= periodicMinInterval {
+			gaps = append(gaps, d)
+			gapStamps = append(gapStamps, events[i].Timestamp)
+		}
+	}
+	for _, group := range findRepeatedDurations(gaps, gapStamps) {
+		findings = append(findings, Finding{
+			Kind:     findingPeriodic,
+			Title:    fmt.Sprintf("Aufwachen in festem Abstand (alle %s)", formatDuration(group.interval)),
+			Evidence: group.evidence,
+			Cause:    "Periodischer Aufweck-Zeitgeber, meist eine geplante Aufgabe mit Wiederholungsintervall.",
+		})
+	}
+
+	return findings
+}
+
+// durationGroup is a set of (nearly) equal durations found by findRepeatedDurations
+type durationGroup struct {
+	interval time.Duration
+	evidence []time.Time
+}
+
+// findRepeatedDurations groups durations that are equal within a tolerance of two
+// minutes (or 2% for long durations) and returns groups with enough repetitions
+func findRepeatedDurations(durations []time.Duration, stamps []time.Time) []durationGroup {
+	used := make([]bool, len(durations))
+	var groups []durationGroup
+	for i, d := range durations {
+		if used[i] {
+			continue
+		}
+		tolerance := d / 50
+		if tolerance < 2*time.Minute {
+			tolerance = 2 * time.Minute
+		}
+		var members []int
+		for j, other := range durations {
+			if !used[j] && (other-d).Abs() <= tolerance {
+				members = append(members, j)
+			}
+		}
+		if len(members) < periodicMinRepeats {
+			continue
+		}
+		group := durationGroup{}
+		values := make([]time.Duration, 0, len(members))
+		for _, j := range members {
+			used[j] = true
+			values = append(values, durations[j])
+			group.evidence = append(group.evidence, stamps[j])
+		}
+		sort.Slice(values, func(a, b int) bool { return values[a] < values[b] })
+		group.interval = values[len(values)/2].Round(time.Minute)
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// detectShortSleepBursts reports several sleeps shorter than a minute in quick succession
+func detectShortSleepBursts(events []WakeEvent) []Finding {
+	var findings []Finding
+	var burst []WakeEvent
+
+	flush := func() {
+		if len(burst) >= shortSleepBurstSize {
+			finding := Finding{
+				Kind:  findingShortSleeps,
+				Title: fmt.Sprintf("%d kurze Schlafphasen unter einer Minute", len(burst)),
+				Cause: "Ein Gerät oder Treiber weckt den PC sofort wieder auf (häufig Netzwerkkarte, Maus oder USB-Gerät).",
+			}
+			sources := make(map[string]int)
+			for _, event := range burst {
+				finding.Evidence = append(finding.Evidence, event.Timestamp)
+				sources[strings.TrimSpace(event.Source)]++
+			}
+			if source := mostFrequent(sources); source != "" {
+				finding.Cause += fmt.Sprintf(" Häufigste Quelle: %s.", source)
+			}
+			findings = append(findings, finding)
+		}
+		burst = nil
+	}
+
+	for _, event := range events {
+		if event.Timestamp.Sub(event.SleepTime) >= shortSleepLimit {
+			continue
+		}
+		if len(burst) > 0 && event.Timestamp.Sub(burst[len(burst)-1].Timestamp) > shortSleepBurstGap {
+			flush()
+		}
+		burst = append(burst, event)
+	}
+	flush()
+
+	return findings
+}
+
+// detectSameMinuteWakes reports wakes that happen at the same local minute on several days
+func detectSameMinuteWakes(events []WakeEvent) []Finding {
+	var order []string
+	days := make(map[string]map[string]bool)
+	evidence := make(map[string][]time.Time)
+	for _, event := range events {
+		local := event.Timestamp.Local()
+		minute := local.Format("15:04")
+		day := local.Format("2006-01-02")
+		if days[minute] == nil {
+			days[minute] = make(map[string]bool)
+			order = append(order, minute)
+		}
+		if !days[minute][day] {
+			days[minute][day] = true
+			evidence[minute] = append(evidence[minute], event.Timestamp)
+		}
+	}
+
+	var findings []Finding
+	for _, minute := range order {
+		if len(days[minute]) < sameMinuteMinDays {
+			continue
+		}
+		title := fmt.Sprintf("Aufwachen täglich um %s Uhr (%d Tage)", minute, len(days[minute]))
+		if hour := evidence[minute][0].Local().Hour(); hour >= 22 || hour < 7 {
+			title = fmt.Sprintf("Aufwachen jede Nacht um %s Uhr (%d Nächte)", minute, len(days[minute]))
+		}
+		findings = append(findings, Finding{
+			Kind:     findingSameMinute,
+			Title:    title,
+			Evidence: evidence[minute],
+			Cause:    "Geplante Aufgabe mit täglichem Trigger und \"Zum Ausführen reaktivieren\" (z.B. Wartung oder Windows Update).",
+		})
+	}
+	return findings
+}
+
+// mostFrequent returns the key with the highest count
+func mostFrequent(counts map[string]int) string {
+	best := ""
+	for key, count := range counts {
+		if count > counts[best] || (count == counts[best] && key < best) {
+			best = key
+		}
+	}
+	return best
+}
+
+// showWakeAnomalies detects and prints unusual wake patterns; the baseline is only read
+func showWakeAnomalies(events []WakeEvent, full bool) {
+	baseline, err := loadWakeBaseline()
+	if err != nil && verboseFlag {
+		printUTF8ln("Hinweis: Konnte Aufweck-Basislinie nicht laden: %v", err)
+	}
+
+	findings := append(detectWakeAnomalies(events, baseline), querySystemFindings()...)
+	if len(findings) > 0 || full {
+		printUTF8ln("\n=== Auffälligkeiten im Aufweckverhalten ===")
+	}
+	printFindings(findings)
+	if len(findings) == 0 && full {
+		printUTF8ln("Keine Auffälligkeiten gefunden.")
+	}
+
+	switch {
+	case len(baseline.Sources) == 0 && len(events) > 0:
+		printUTF8ln("Hinweis: Keine Aufweck-Basislinie vorhanden, neue Aufweckquellen werden nicht erkannt. Bekannte Quellen übernehmen: SleepRight baseline update")
+	case hasFinding(findings, findingNewSource):
+		printUTF8ln("Neue Aufweckquellen als bekannt übernehmen: SleepRight baseline update")
+	}
+}
+
+// hasFinding reports whether findings contain a finding of the given kind
+func hasFinding(findings []Finding, kind string) bool {
+	for _, finding := range findings {
+		if finding.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// showWakeBaseline prints the wake sources of the baseline, oldest first
+func showWakeBaseline() error {
+	baseline, err := loadWakeBaseline()
+	if err != nil {
+		return fmt.Errorf("Fehler beim Laden der Aufweck-Basislinie: %w", err)
+	}
+	path, _ := wakeBaselinePath()
+	printUTF8ln("Aufweck-Basislinie (%s): %d Quellen", path, len(baseline.Sources))
+	sources := make([]string, 0, len(baseline.Sources))
+	for source := range baseline.Sources {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return baseline.Sources[sources[i]].Before(baseline.Sources[sources[j]])
+	})
+	for _, source := range sources {
+		printUTF8ln("  %s  %s", baseline.Sources[source].Local().Format("02.01.2006 15:04"), source)
+	}
+	return nil
+}
+
+// updateWakeBaseline accepts all wake sources in the event log as known
+func updateWakeBaseline() error {
+	baseline, err := loadWakeBaseline()
+	if err != nil {
+		return err
+	}
+	events, err := queryEventLogWakeEvents(wakeEventQueryCount)
+	if err != nil {
+		return fmt.Errorf("failed to read wake events: %w", err)
+	}
+	known := len(baseline.Sources)
+	baseline.learn(events)
+	if err := baseline.save(); err != nil {
+		return err
+	}
+	fmt.Printf("Baseline updated: %d wake sources (%d new).\n", len(baseline.Sources), len(baseline.Sources)-known)
+	return nil
+}
+
+// runBaselineCommand shows, updates or removes the wake source baseline
+func runBaselineCommand(args []string) int {
+	var err error
+	switch {
+	case len(args) == 0:
+		err = showWakeBaseline()
+	case len(args) == 1 && args[0] == "update":
+		err = updateWakeBaseline()
+	case len(args) == 1 && args[0] == "reset":
+		var path string
+		if path, err = wakeBaselinePath(); err == nil {
+			if err = os.Remove(path); errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		}
+		if err == nil {
+			fmt.Println("Baseline removed.")
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight baseline [update|reset]\n")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printFindings prints findings with their evidence and suggested cause
+func printFindings(findings []Finding) {
+	const maxEvidence = 5
+	for i, finding := range findings {
+		printUTF8ln("  %d. %s", i+1, finding.Title)
+		if len(finding.Evidence) > 0 {
+			var stamps []string
+			for j, t := range finding.Evidence {
+				if j >= maxEvidence {
+					stamps = append(stamps, fmt.Sprintf("(+%d weitere)", len(finding.Evidence)-maxEvidence))
+					break
+				}
+				stamps = append(stamps, t.Local().Format("02.01.2006 15:04:05"))
+			}
+			printUTF8ln("     Belege: %s", strings.Join(stamps, ", "))
+		}
+		printUTF8ln("     Vermutete Ursache: %s", finding.Cause)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wakeAt is a wake event on day d of March 2026 at hh:mm local time after sleeping
+// for the given duration
+func wakeAt(d, hh, mm int, slept time.Duration, source string) WakeEvent {
+	wake := time.Date(2026, 3, d, hh, mm, 0, 0, time.Local)
+	return WakeEvent{Timestamp: wake, SleepTime: wake.Add(-slept), Source: source}
+}
+
+// summarizeFindings lists the kind and evidence count of each finding
+func summarizeFindings(findings []Finding) string {
+	parts := make([]string, len(findings))
+	for i, finding := range findings {
+		parts[i] = fmt.Sprintf("%s:%d", finding.Kind, len(finding.Evidence))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestDetectWakeAnomalies(t *testing.T) {
+	known := &WakeBaseline{Sources: map[string]time.Time{"hid keyboard device": {}}}
+	short := 20 * time.Second
+
+	tests := []struct {
+		name     string
+		events   []WakeEvent
+		baseline *WakeBaseline
+		want     string
+	}{
+		// New wake sources
+		{"no baseline", []WakeEvent{wakeAt(2, 8, 0, 7*time.Hour, "Intel(R) Ethernet")}, nil, ""},
+		{"empty baseline", []WakeEvent{wakeAt(2, 8, 0, 7*time.Hour, "Intel(R) Ethernet")}, &WakeBaseline{}, ""},
+		{"known source", []WakeEvent{wakeAt(2, 8, 0, 7*time.Hour, "  HID   Keyboard Device ")}, known, ""},
+		{"new source", []WakeEvent{
+			wakeAt(2, 8, 0, 7*time.Hour, "HID Keyboard Device"),
+			wakeAt(3, 9, 10, 5*time.Hour, "Intel(R) Ethernet"),
+			wakeAt(5, 7, 45, 9*time.Hour, "intel(r)  ethernet"),
+		}, known, "new-source:2"},
+
+		// Equal sleep durations, the wakes themselves at irregular distances
+		{"sleep duration twice", []WakeEvent{
+			wakeAt(2, 3, 0, 2*time.Hour, ""),
+			wakeAt(3, 5, 17, 2*time.Hour, ""),
+		}, nil, ""},
+		{"sleep duration exactly three times", []WakeEvent{
+			wakeAt(2, 3, 0, 2*time.Hour, ""),
+			wakeAt(3, 5, 17, 2*time.Hour+time.Minute, ""),
+			wakeAt(5, 1, 43, 2*time.Hour-2*time.Minute, ""),
+		}, nil, "periodic:3"},
+		{"sleep duration outside the tolerance", []WakeEvent{
+			wakeAt(2, 3, 0, 2*time.Hour, ""),
+			wakeAt(3, 5, 17, 2*time.Hour+time.Minute, ""),
+			wakeAt(5, 1, 43, 2*time.Hour+4*time.Minute, ""),
+		}, nil, ""},
+		{"equal sleeps too short for a timer", []WakeEvent{
+			wakeAt(2, 3, 0, 4*time.Minute, ""),
+			wakeAt(3, 5, 17, 4*time.Minute, ""),
+			wakeAt(5, 1, 43, 4*time.Minute, ""),
+		}, nil, ""},
+
+		// Equal distances between wakes, different sleep durations
+		{"distance exactly three times", []WakeEvent{
+			wakeAt(2, 1, 0, 10*time.Minute, ""),
+			wakeAt(2, 3, 0, 30*time.Minute, ""),
+			wakeAt(2, 5, 0, 50*time.Minute, ""),
+			wakeAt(2, 7, 0, 70*time.Minute, ""),
+		}, nil, "periodic:3"},
+		{"distance twice", []WakeEvent{
+			wakeAt(2, 1, 0, 10*time.Minute, ""),
+			wakeAt(2, 3, 0, 30*time.Minute, ""),
+			wakeAt(2, 5, 0, 50*time.Minute, ""),
+		}, nil, ""},
+
+		// Bursts of short sleeps
+		{"short sleeps at the burst gap", []WakeEvent{
+			wakeAt(2, 10, 0, short, "Intel(R) Ethernet"),
+			wakeAt(2, 10, 30, short, "Intel(R) Ethernet"),
+			wakeAt(2, 11, 0, short, "USB Root Hub"),
+		}, nil, "short-sleeps:3"},
+		{"short sleeps beyond the burst gap", []WakeEvent{
+			wakeAt(2, 10, 0, short, ""),
+			wakeAt(2, 10, 30, short, ""),
+			wakeAt(2, 11, 1, short, ""),
+		}, nil, ""},
+		{"two short sleeps", []WakeEvent{
+			wakeAt(2, 10, 0, short, ""),
+			wakeAt(2, 10, 5, short, ""),
+		}, nil, ""},
+		{"sleep of a minute is not short", []WakeEvent{
+			wakeAt(2, 10, 0, time.Minute, ""),
+			wakeAt(2, 10, 5, time.Minute, ""),
+			wakeAt(2, 10, 12, time.Minute, ""),
+		}, nil, ""},
+
+		// Wakes at the same minute
+		{"same minute on three nights", []WakeEvent{
+			wakeAt(2, 3, 12, 1*time.Hour, ""),
+			wakeAt(3, 3, 12, 3*time.Hour, ""),
+			wakeAt(6, 3, 12, 5*time.Hour, ""),
+		}, nil, "same-minute:3"},
+		{"same minute on two days", []WakeEvent{
+			wakeAt(2, 3, 12, 1*time.Hour, ""),
+			wakeAt(2, 3, 12, 3*time.Hour, ""),
+			wakeAt(3, 3, 12, 5*time.Hour, ""),
+		}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeFindings(detectWakeAnomalies(tt.events, tt.baseline)); got != tt.want {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectWakeAnomaliesDetails(t *testing.T) {
+	// Events in any order; the evidence is sorted by time
+	events := []WakeEvent{
+		wakeAt(6, 14, 30, 3*time.Hour, "USB Root Hub"),
+		wakeAt(2, 14, 30, 1*time.Hour, "USB Root Hub"),
+		wakeAt(3, 14, 30, 5*time.Hour, "Intel(R) Ethernet"),
+	}
+	findings := detectWakeAnomalies(events, nil)
+	if len(findings) != 1 {
+		t.Fatalf("findings = %s", summarizeFindings(findings))
+	}
+	if findings[0].Title != "Aufwachen täglich um 14:30 Uhr (3 Tage)" {
+		t.Errorf("Title = %q", findings[0].Title)
+	}
+	if !findings[0].Evidence[0].Equal(events[1].Timestamp) || !findings[0].Evidence[2].Equal(events[0].Timestamp) {
+		t.Errorf("Evidence = %v", findings[0].Evidence)
+	}
+
+	burst := detectShortSleepBursts([]WakeEvent{
+		wakeAt(2, 10, 0, time.Second, "Intel(R) Ethernet"),
+		wakeAt(2, 10, 1, time.Second, "Intel(R) Ethernet"),
+		wakeAt(2, 10, 2, time.Second, "USB Root Hub"),
+	})
+	if len(burst) != 1 || !strings.HasSuffix(burst[0].Cause, "Häufigste Quelle: Intel(R) Ethernet.") {
+		t.Errorf("burst = %+v", burst)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The audit log is rotated when it reaches auditMaxSize; auditKeep old files are kept
+const (
+	auditMaxSize = 1024 * 1024
+	auditKeep    = 3
+)
+
+// AuditEntry records one change SleepRight made to the system
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Elevated bool      `json:"elevated"`
+	Action   string    `json:"action"` // kind of change, see auditChange
+	Target   string    `json:"target,omitempty"`
+	Command  string    `json:"command,omitempty"`
+	Previous string    `json:"previous,omitempty"`
+	New      string    `json:"new,omitempty"`
+	Result   string    `json:"result"` // "ok" or "failed"
+	Error    string    `json:"error,omitempty"`
+}
+
+// auditChange describes a change before it is made. Action is one of device-wake,
+// setting, scheme, attribute, hibernation, registry or task.
+type auditChange struct {
+	Action   string
+	Target   string
+	Previous string
+	New      string
+}
+
+// auditUser is the account SleepRight runs as, determined once
+var auditUser = func() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USERNAME")
+}()
+
+// auditElevated reports whether SleepRight runs elevated, determined once
+var auditElevated = sync.OnceValue(isAdmin)
+
+// newAuditEntry builds the entry for a change and its outcome
+func newAuditEntry(change auditChange, command string, err error, now time.Time, userName string, elevated bool) AuditEntry {
+	entry := AuditEntry{
+		Time:     now,
+		User:     userName,
+		Elevated: elevated,
+		Action:   change.Action,
+		Target:   change.Target,
+		Command:  command,
+		Previous: change.Previous,
+		New:      change.New,
+		Result:   "ok",
+	}
+	if err != nil {
+		entry.Result, entry.Error = "failed", err.Error()
+	}
+	return entry
+}
+
+// recordAudit writes the entry for a change to the audit log and, if enabled, to the
+// Application event log. Failures to write the log never fail the change itself.
+func recordAudit(change auditChange, command string, err error) {
+	entry := newAuditEntry(change, command, err, time.Now(), auditUser, auditElevated())
+	if writeErr := appendAuditEntry(entry); writeErr != nil && verboseFlag {
+		fmt.Printf("  Warning: could not write audit log: %v\n", writeErr)
+	}
+	writeEvent(auditEvent(entry))
+}
+
+// runAudited runs a command that changes the system and records it in the audit log.
+// The error includes the command output.
+func runAudited(change auditChange, name string, args ...string) error {
+	_, err := runAuditedOutput(change, name, args...)
+	return err
+}
+
+// runAuditedOutput is runAudited for commands whose output is needed
+func runAuditedOutput(change auditChange, name string, args ...string) (string, error) {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	text := strings.TrimSpace(decodeCP1252(string(output)))
+	if err != nil && text != "" {
+		err = fmt.Errorf("%w: %s", err, text)
+	}
+	recordAudit(change, formatCommandLine(name, args), err)
+	return text, err
+}
+
+// formatCommandLine joins a command and its arguments, quoting arguments with spaces
+func formatCommandLine(name string, args []string) string {
+	parts := []string{name}
+	for _, arg := range args {
+		if containsSpace(arg) {
+			arg = `"` + arg + `"`
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// auditLogPath returns the location of the current audit log
+func auditLogPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// appendAuditEntry appends an entry as JSON line, rotating the log first if needed
+func appendAuditEntry(entry AuditEntry) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+	if err := rotateLog(path, auditMaxSize, auditKeep); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// rotatedLogName returns the name of the n-th old log: audit.jsonl -> audit.1.jsonl
+func rotatedLogName(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// rotateLog renames path to its first rotated name once it reached maxSize, shifting
+// older files and dropping the oldest beyond keep
+func rotateLog(path string, maxSize int64, keep int) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < maxSize {
+		return nil
+	}
+	os.Remove(rotatedLogName(path, keep))
+	for n := keep - 1; n >= 1; n-- {
+		if err := os.Rename(rotatedLogName(path, n), rotatedLogName(path, n+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log: %w", err)
+		}
+	}
+	if err := os.Rename(path, rotatedLogName(path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate log: %w", err)
+	}
+	return nil
+}
+
+// readAuditEntries reads the entries of the current audit log
+func readAuditEntries() ([]AuditEntry, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// auditedRegistry records every registry change in the audit log
+type auditedRegistry struct {
+	registryStore
+}
+
+func (r auditedRegistry) SetDWORD(path, name string, value uint32) error {
+	previous, found, _ := r.GetDWORD(path, name)
+	change := auditChange{Action: "registry", Target: `HKLM\` + path + `\` + name, New: fmt.Sprintf("%d", value)}
+	if found {
+		change.Previous = fmt.Sprintf("%d", previous)
+	}
+	err := r.registryStore.SetDWORD(path, name, value)
+	recordAudit(change, "", err)
+	return err
+}
+
+func (r auditedRegistry) DeleteValue(path, name string) error {
+	previous, found, _ := r.GetDWORD(path, name)
+	if !found {
+		return r.registryStore.DeleteValue(path, name)
+	}
+	change := auditChange{Action: "registry", Target: `HKLM\` + path + `\` + name, Previous: fmt.Sprintf("%d", previous), New: "deleted"}
+	err := r.registryStore.DeleteValue(path, name)
+	recordAudit(change, "", err)
+	return err
+}
+
+// showAuditLog prints the last entries of the audit log
+func showAuditLog(count int) error {
+	entries, err := readAuditEntries()
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen des Änderungsprotokolls: %w", err)
+	}
+	path, _ := auditLogPath()
+	printUTF8ln("Änderungsprotokoll (%s): %d Einträge", path, len(entries))
+	if len(entries) > count {
+		entries = entries[len(entries)-count:]
+	}
+	for _, entry := range entries {
+		admin := ""
+		if entry.Elevated {
+			admin = ", Administrator"
+		}
+		printUTF8ln("  %s %s (%s%s)", entry.Time.Local().Format("02.01.2006 15:04:05"), entry.Action, entry.User, admin)
+		if entry.Target != "" {
+			printUTF8ln("    Ziel: %s", entry.Target)
+		}
+		if entry.Previous != "" || entry.New != "" {
+			printUTF8ln("    Vorher: %s | Nachher: %s", entry.Previous, entry.New)
+		}
+		if entry.Command != "" {
+			printUTF8ln("    Befehl: %s", entry.Command)
+		}
+		if entry.Error != "" {
+			printUTF8ln("    Fehler: %s", entry.Error)
+		}
+	}
+	return nil
+}
+
+// runAuditCommand shows the audit log
+func runAuditCommand(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight audit\n")
+		return 1
+	}
+	if err := showAuditLog(20); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Deviation is a difference between the desired profile and the current state
+type Deviation struct {
+	Item string
+	Want string
+	Have string
+}
+
+// SystemState is the current state compared by "SleepRight check"
+type SystemState struct {
+	ActiveScheme     string
+	Scheme           *PowerScheme // active scheme including hidden settings
+	Hibernation      *HibernationState
+	FastStartup      FastStartupState
+	ModernStandby    ModernStandbyState
+	WakeArmed        []string
+	MaintenanceSaved string // window stored in maintenance.json, "" if none
+	MaintenanceTasks bool   // both SleepRight maintenance tasks exist
+}
+
+// compareProfile lists all deviations of the state from the profile at the given time
+func compareProfile(profile *Profile, state *SystemState, now time.Time) []Deviation {
+	var deviations []Deviation
+	add := func(item, want, have string) {
+		deviations = append(deviations, Deviation{Item: item, Want: want, Have: have})
+	}
+
+	if !strings.EqualFold(state.ActiveScheme, sleepRightSchemeName) {
+		add("scheme", sleepRightSchemeName, state.ActiveScheme)
+	}
+
+	// A maintenance window overrides the wake-timers setting of the profile
+	wanted := append([]ProfileSetting(nil), profile.Settings...)
+	if value := profile.Options["maintenance-window"]; value != "" {
+		if window, err := parseMaintenanceWindow(value); err == nil {
+			if state.MaintenanceSaved != window.String() || !state.MaintenanceTasks {
+				add("maintenance-window", window.String(), formatMaintenanceInstall(state))
+			}
+			if window.StateAt(now).WakeTimersEnabled {
+				alias := findSettingAlias("wake-timers")
+				enabled := ProfileSetting{Alias: alias, AC: 1, DC: 1, HasAC: true, HasDC: true}
+				replaced := false
+				for i := range wanted {
+					if wanted[i].Alias == alias {
+						wanted[i], replaced = enabled, true
+					}
+				}
+				if !replaced {
+					wanted = append(wanted, enabled)
+				}
+			}
+		}
+	}
+
+	for _, want := range wanted {
+		alias := want.Alias
+		var setting *PowerSetting
+		if state.Scheme != nil {
+			_, setting = state.Scheme.Find(alias.Subgroup, alias.Setting)
+		}
+		if want.HasAC && (setting == nil || !setting.HasAC || setting.AC != want.AC) {
+			add(alias.Name+".ac", alias.FormatValue(want.AC), formatHaveIndex(alias, setting, true))
+		}
+		if want.HasDC && (setting == nil || !setting.HasDC || setting.DC != want.DC) {
+			add(alias.Name+".dc", alias.FormatValue(want.DC), formatHaveIndex(alias, setting, false))
+		}
+	}
+
+	if mode := profile.Options["hibernation"]; mode != "" && state.Hibernation != nil {
+		// "on" only enables hibernation and keeps the type of the hibernate file
+		if have := hibernationMode(state.Hibernation); have != mode && !(mode == "on" && have != "off") {
+			add("hibernation", mode, have)
+		}
+	}
+
+	if mode := profile.Options["fast-startup"]; mode != "" {
+		have := "off"
+		if state.FastStartup.Enabled {
+			have = "on"
+		}
+		if have != mode {
+			add("fast-startup", mode, have)
+		}
+	}
+
+	switch profile.Options["modern-standby"] {
+	case "off":
+		if state.ModernStandby.S0Available {
+			add("modern-standby", "off", "on")
+		}
+	case "no-network":
+		alias := findSettingAlias("standby-network")
+		var setting *PowerSetting
+		if state.Scheme != nil {
+			_, setting = state.Scheme.Find(alias.Subgroup, alias.Setting)
+		}
+		if state.ModernStandby.S0Available && setting != nil && (setting.AC != 0 || setting.DC != 0) {
+			add("modern-standby", "no-network", fmt.Sprintf("%s (AC) / %s (DC)", alias.FormatValue(setting.AC), alias.FormatValue(setting.DC)))
+		}
+	}
+
+	allowed := profile.WakeDevices()
+	for _, device := range state.WakeArmed {
+		if !allowed.Allows(device) {
+			add("wake-device", "nicht aktiviert", device)
+		}
+	}
+	return deviations
+}
+
+// hibernationMode returns the hibernation state as profile value. HiberFileType stays
+// in the registry after "powercfg /h off", so it only counts while hibernation is
+// enabled. A reduced hibernate file does not support hibernation, so powercfg /a lists
+// it as unavailable; the hibernate file shows that hibernation is still enabled.
+func hibernationMode(state *HibernationState) string {
+	switch {
+	case !state.Available && state.FileSize == 0:
+		return "off"
+	case state.FileType == "reduced":
+		return "reduced"
+	case state.Available:
+		return "full"
+	default:
+		return "off"
+	}
+}
+
+// formatHaveIndex formats the current AC or DC value of a setting
+func formatHaveIndex(alias *SettingAlias, setting *PowerSetting, ac bool) string {
+	if setting == nil {
+		return "nicht vorhanden"
+	}
+	if ac {
+		return formatAliasIndex(alias, setting.AC, setting.HasAC)
+	}
+	return formatAliasIndex(alias, setting.DC, setting.HasDC)
+}
+
+// formatMaintenanceInstall describes the installed maintenance window
+func formatMaintenanceInstall(state *SystemState) string {
+	switch {
+	case state.MaintenanceSaved == "":
+		return "nicht konfiguriert"
+	case !state.MaintenanceTasks:
+		return state.MaintenanceSaved + " (Aufgaben fehlen)"
+	default:
+		return state.MaintenanceSaved
+	}
+}
+
+// querySystemState collects the state compared by "SleepRight check"
+func querySystemState() (*SystemState, error) {
+	state := &SystemState{}
+
+	schemes, err := listPowerSchemes()
+	if err != nil {
+		return nil, err
+	}
+	for _, scheme := range schemes {
+		if scheme.Active {
+			state.ActiveScheme = scheme.Name
+		}
+	}
+	if state.Scheme, err = queryPowerScheme("", true); err != nil {
+		return nil, err
+	}
+	if state.Hibernation, err = queryHibernationState(); err != nil {
+		return nil, err
+	}
+	if state.FastStartup, err = queryFastStartup(systemRegistry); err != nil {
+		return nil, err
+	}
+	if state.ModernStandby, err = queryModernStandby(systemRegistry); err != nil {
+		return nil, err
+	}
+
+	if state.WakeArmed, err = (powercfgProbe{}).WakeArmed(); err != nil {
+		return nil, fmt.Errorf("Fehler beim Abfragen der Weckgeräte: %w", err)
+	}
+
+	if config, window, err := loadMaintenanceConfig(); err == nil && config != nil {
+		state.MaintenanceSaved = window.String()
+		state.MaintenanceTasks = true
+		for _, name := range []string{maintenanceWakeTask, maintenanceApplyTask} {
+			if _, err := runCommandWithEncoding("schtasks", "/query", "/tn", maintenanceTaskFolder+name); err != nil {
+				state.MaintenanceTasks = false
+			}
+		}
+	}
+	return state, nil
+}
+
+func setupCheckCommand(fs *flag.FlagSet) {
+	fs.StringVar(&profilePath, "profile", "", "Profile to check against (default: built-in profile)")
+}
+
+// runCheckCommand compares the current state with the profile. Exit code 0 means
+// compliant, 1 deviations found and 2 that the check could not be run.
+func runCheckCommand(args []string) int {
+	profile, err := loadProfile(profilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	state, err := querySystemState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		writeEvent(errorEvent("check", err))
+		return 2
+	}
+
+	deviations := compareProfile(profile, state, time.Now())
+	if len(deviations) == 0 {
+		printUTF8ln("Konform: Der aktuelle Zustand entspricht dem Profil.")
+		return 0
+	}
+	writeEvent(driftEvent(eventDriftDetected, deviations))
+	printUTF8ln("Abweichungen vom Profil: %d", len(deviations))
+	for _, d := range deviations {
+		printUTF8ln("  %s: soll %s, ist %s", d.Item, d.Want, d.Have)
+	}
+	return 1
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompareProfileHibernation(t *testing.T) {
+	var (
+		off           = &HibernationState{}
+		offStaleType  = &HibernationState{FileType: "reduced"} // HiberFileType left after /h off
+		full          = &HibernationState{Available: true, FileSize: 13 << 30, FileType: "full"}
+		fullNoType    = &HibernationState{Available: true, FileSize: 13 << 30}
+		reduced       = &HibernationState{FileSize: 6 << 30, FileType: "reduced"}
+		unknownReason = &HibernationState{FileSize: 13 << 30, FileType: "full"} // e.g. disabled by policy
+	)
+	tests := []struct {
+		mode  string
+		state *HibernationState
+		want  string // reported state, "" if compliant
+	}{
+		{"on", full, ""},
+		{"on", fullNoType, ""},
+		{"on", reduced, ""},
+		{"on", off, "off"},
+		{"on", offStaleType, "off"},
+		{"off", off, ""},
+		{"off", offStaleType, ""},
+		{"off", full, "full"},
+		{"off", reduced, "reduced"},
+		{"full", full, ""},
+		{"full", reduced, "reduced"},
+		{"full", unknownReason, "off"},
+		{"reduced", reduced, ""},
+		{"reduced", full, "full"},
+		{"reduced", offStaleType, "off"},
+	}
+	for _, tt := range tests {
+		profile, err := parseProfile("hibernation: " + tt.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state := &SystemState{ActiveScheme: sleepRightSchemeName, Hibernation: tt.state}
+		got := ""
+		for _, d := range compareProfile(profile, state, time.Now()) {
+			if d.Item == "hibernation" {
+				got = d.Have
+			}
+		}
+		if got != tt.want {
+			t.Errorf("hibernation: %s with %+v: drift %q, want %q", tt.mode, *tt.state, got, tt.want)
+		}
+	}
+}
+
+// sleepScheme returns a scheme with the sleep timeout and the wake timers setting
+func sleepScheme(sleepAC, sleepDC, wakeTimersAC, wakeTimersDC uint32) *PowerScheme {
+	sleep, wakeTimers := findSettingAlias("sleep"), findSettingAlias("wake-timers")
+	return &PowerScheme{Name: sleepRightSchemeName, Subgroups: []PowerSubgroup{{
+		GUID: subgroupSleep,
+		Settings: []PowerSetting{
+			{GUID: sleep.Setting, AC: sleepAC, DC: sleepDC, HasAC: true, HasDC: true},
+			{GUID: wakeTimers.Setting, AC: wakeTimersAC, DC: wakeTimersDC, HasAC: true, HasDC: true},
+		},
+	}}}
+}
+
+func TestCompareProfile(t *testing.T) {
+	const profileText = "sleep: 30m\nwake-timers: disabled\nwake-timers.dc: important\n"
+	const windowProfile = profileText + "maintenance-window: Wed 02:00-03:00\n"
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 14.10.2026 is a Wednesday, wake timers are enabled from 22:00 the evening before
+	beforeArming := time.Date(2026, 10, 13, 12, 0, 0, 0, berlin)
+	armed := time.Date(2026, 10, 14, 1, 0, 0, 0, berlin)
+
+	tests := []struct {
+		name    string
+		profile string
+		state   SystemState
+		now     time.Time
+		want    []Deviation
+	}{
+		{"compliant", profileText,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2)}, beforeArming, nil},
+		{"other scheme active", profileText,
+			SystemState{ActiveScheme: "Balanced", Scheme: sleepScheme(1800, 1800, 0, 2)}, beforeArming,
+			[]Deviation{{"scheme", sleepRightSchemeName, "Balanced"}}},
+		{"settings differ", profileText,
+			SystemState{Scheme: sleepScheme(0, 1800, 1, 1)}, beforeArming,
+			[]Deviation{
+				{"sleep.ac", "30 Minuten", "Deaktiviert"},
+				{"wake-timers.ac", "disabled", "enabled"},
+				{"wake-timers.dc", "important", "enabled"},
+			}},
+		{"setting missing", "hibernate: 3h\n",
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2)}, beforeArming,
+			[]Deviation{{"hibernate.ac", "3 Stunden", "nicht vorhanden"}, {"hibernate.dc", "3 Stunden", "nicht vorhanden"}}},
+		{"keyboard and network may wake", profileText,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2),
+				WakeArmed: []string{"HID Keyboard Device", "Intel(R) Ethernet Connection I219-V", "Realtek PCIe GbE Family Controller"}},
+			beforeArming, nil},
+		{"other wake devices", profileText,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2),
+				WakeArmed: []string{"HID-konforme Maus", "HID Keyboard Device", "USB Root Hub (USB 3.0)"}},
+			beforeArming,
+			[]Deviation{{"wake-device", "nicht aktiviert", "HID-konforme Maus"}, {"wake-device", "nicht aktiviert", "USB Root Hub (USB 3.0)"}}},
+		{"only the network may wake", profileText + "wake-devices: network\n",
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2),
+				WakeArmed: []string{"HID Keyboard Device", "Intel(R) Ethernet Connection I219-V"}},
+			beforeArming,
+			[]Deviation{{"wake-device", "nicht aktiviert", "HID Keyboard Device"}}},
+		{"maintenance window installed", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2), MaintenanceSaved: "Wed 02:00-03:00", MaintenanceTasks: true},
+			beforeArming, nil},
+		{"maintenance window not installed", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2)}, beforeArming,
+			[]Deviation{{"maintenance-window", "Wed 02:00-03:00", "nicht konfiguriert"}}},
+		{"maintenance tasks missing", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2), MaintenanceSaved: "Wed 02:00-03:00"}, beforeArming,
+			[]Deviation{{"maintenance-window", "Wed 02:00-03:00", "Wed 02:00-03:00 (Aufgaben fehlen)"}}},
+		{"other maintenance window installed", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2), MaintenanceSaved: "daily 03:00-04:00", MaintenanceTasks: true},
+			beforeArming,
+			[]Deviation{{"maintenance-window", "Wed 02:00-03:00", "daily 03:00-04:00"}}},
+		{"wake timers enabled for the window", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 1, 1), MaintenanceSaved: "Wed 02:00-03:00", MaintenanceTasks: true},
+			armed, nil},
+		{"wake timers not enabled for the window", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 0, 2), MaintenanceSaved: "Wed 02:00-03:00", MaintenanceTasks: true},
+			armed,
+			[]Deviation{{"wake-timers.ac", "enabled", "disabled"}, {"wake-timers.dc", "enabled", "important"}}},
+		{"wake timers left enabled after the window", windowProfile,
+			SystemState{Scheme: sleepScheme(1800, 1800, 1, 1), MaintenanceSaved: "Wed 02:00-03:00", MaintenanceTasks: true},
+			beforeArming,
+			[]Deviation{{"wake-timers.ac", "disabled", "enabled"}, {"wake-timers.dc", "important", "enabled"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := parseProfile(tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := tt.state
+			if state.ActiveScheme == "" {
+				state.ActiveScheme = sleepRightSchemeName
+			}
+			got := compareProfile(profile, &state, tt.now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareProfile =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
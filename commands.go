@@ -0,0 +1,220 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command is a SleepRight subcommand such as "SleepRight report -o report.html".
+// Subcommands have their own flag set; the classic -info/-configure flags keep
+// working without a subcommand.
+type command struct {
+	name       string
+	args       string // argument synopsis for the usage text
+	summary    string
+	help       string // details shown after the summary by "SleepRight <command> -h" (may be empty)
+	needsAdmin bool
+	setup      func(fs *flag.FlagSet) // registers the command's own flags (may be nil)
+	run        func(args []string) int
+}
+
+// commands lists all subcommands in the order shown by showUsage
+var commands = []command{
+	{
+		name:       "report",
+		args:       "-o <file.html> [-full]",
+		summary:    "Write a self-contained HTML report",
+		needsAdmin: true,
+		setup:      setupReportCommand,
+		run:        runReportCommand,
+	},
+	{
+		name:       "baseline",
+		args:       "[update|reset]",
+		summary:    "Show the known wake sources or accept the wake sources in the event log as known",
+		needsAdmin: true,
+		run:        runBaselineCommand,
+	},
+	{
+		name:       "dump",
+		args:       "[-hidden] [-o snapshot.json] [scheme]",
+		summary:    "Show all settings of a power scheme (default: active scheme)",
+		needsAdmin: false,
+		setup:      setupDumpCommand,
+		run:        runDumpCommand,
+	},
+	{
+		name:       "diff",
+		args:       "[-hidden] [a] [b]",
+		summary:    "Compare two power schemes or snapshots setting by setting",
+		needsAdmin: false,
+		setup:      setupDiffCommand,
+		run:        runDiffCommand,
+	},
+	{
+		name:       "get",
+		args:       "<setting>",
+		summary:    "Show a power setting of the active scheme (name, GUID or alias)",
+		needsAdmin: false,
+		run:        runGetCommand,
+	},
+	{
+		name:       "set",
+		args:       "<setting> ac=<value> dc=<value>",
+		summary:    "Change a power setting of the active scheme",
+		needsAdmin: true,
+		run:        runSetCommand,
+	},
+	{
+		name:       "hidden",
+		args:       "",
+		summary:    "List power settings hidden in the Control Panel",
+		needsAdmin: false,
+		run:        runHiddenCommand,
+	},
+	{
+		name:       "unhide",
+		args:       "<setting>...",
+		summary:    "Show power settings in the Control Panel",
+		needsAdmin: true,
+		run:        runUnhideCommand,
+	},
+	{
+		name:       "hide",
+		args:       "<setting>...",
+		summary:    "Hide power settings in the Control Panel again",
+		needsAdmin: true,
+		run:        runHideCommand,
+	},
+	{
+		name:       "hibernation",
+		args:       "[on|off|full|reduced]",
+		summary:    "Show the hibernation state or enable/disable hibernation and set the hibernate file type",
+		needsAdmin: true,
+		run:        runHibernationCommand,
+	},
+	{
+		name:       "modern-standby",
+		args:       "[undo]",
+		summary:    "Show the Modern Standby state or undo SleepRight's Modern Standby changes",
+		needsAdmin: true,
+		run:        runModernStandbyCommand,
+	},
+	{
+		name:       "tasks",
+		args:       "[disable <name|pattern>...]",
+		summary:    "List scheduled tasks that may wake the computer or stop them from waking it",
+		needsAdmin: true,
+		run:        runTasksCommand,
+	},
+	{
+		name:    "maintenance",
+		args:    "[apply|hold|remove]",
+		summary: "Show the maintenance window or run its steps (used by the SleepRight tasks)",
+		help: "Wake timers are enabled 4 hours before the window and restored when it ends.\n" +
+			"Until then any wake timer may wake the computer, not only the SleepRight task;\n" +
+			"\"important wake timers only\" would keep the task from waking it as well.\n" +
+			"A computer that goes to sleep more than 4 hours before the window is not woken for it.",
+		needsAdmin: true,
+		run:        runMaintenanceCommand,
+	},
+	{
+		name:       "check",
+		args:       "[-profile <file>]",
+		summary:    "Compare the current state with a profile; exit code 0 if compliant, 1 on deviations",
+		needsAdmin: false,
+		setup:      setupCheckCommand,
+		run:        runCheckCommand,
+	},
+	{
+		name:       "service",
+		args:       "[-profile <file>] [-interval 15m] install|uninstall|run",
+		summary:    "Install the Windows service that re-applies the profile when the configuration drifts",
+		needsAdmin: true,
+		setup:      setupServiceCommand,
+		run:        runServiceCommand,
+	},
+	{
+		name:       "watch",
+		args:       "[-profile <file>]",
+		summary:    "Stay resident, record the wake source after every resume and re-check wake devices",
+		needsAdmin: false,
+		setup:      setupWatchCommand,
+		run:        runWatchCommand,
+	},
+	{
+		name:       "audit",
+		args:       "",
+		summary:    "Show the log of changes made by SleepRight",
+		needsAdmin: true,
+		run:        runAuditCommand,
+	},
+	{
+		name:       "eventlog",
+		args:       "[on|off]",
+		summary:    "List the event IDs SleepRight writes or install/remove its Application log event source",
+		needsAdmin: true,
+		run:        runEventLogCommand,
+	},
+}
+
+// findCommand returns the subcommand with the given name or nil
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// runCommand parses the flags of a subcommand, elevates if necessary, runs it and
+// exits with its exit code
+func runCommand(cmd *command, args []string) {
+	fs := flag.NewFlagSet("SleepRight "+cmd.name, flag.ExitOnError)
+	fs.BoolVar(&verboseFlag, "verbose", false, "Verbose output")
+	fs.BoolVar(&verboseFlag, "v", false, "Verbose output (short)")
+	fs.BoolVar(&debugFlag, "debug", false, "Debug mode: show all external command calls")
+	fs.StringVar(&childModeFlag, "child-mode", "", "Internal flag: pipe name for elevated instance")
+	if cmd.setup != nil {
+		cmd.setup(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight %s %s\n\n%s\n\n", cmd.name, cmd.args, cmd.summary)
+		if cmd.help != "" {
+			fmt.Fprintf(os.Stderr, "%s\n\n", cmd.help)
+		}
+		fmt.Fprintf(os.Stderr, "OPTIONS:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// Handle child mode (elevated instance) - redirect output to pipe
+	if childModeFlag != "" {
+		if err := runAsChild(childModeFlag); err != nil {
+			os.Exit(1)
+		}
+	}
+
+	if cmd.needsAdmin && !isAdmin() {
+		if err := runAsAdminWithPipe(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to request administrator privileges: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Please run this program as administrator.\n")
+			os.Exit(1)
+		}
+		// Never reached as runAsAdminWithPipe will exit the current process
+		os.Exit(0)
+	}
+
+	fmt.Printf("SleepRight v%s (Build: %s)\n", Version, BuildTime)
+
+	exitCode := cmd.run(fs.Args())
+
+	// If in child mode, let CloseChildMode send the exit code and exit
+	if childModeFlag != "" {
+		childExitCode = exitCode
+		CloseChildMode()
+	}
+	os.Exit(exitCode)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SettingDiff is a setting whose AC or DC value differs between two power schemes.
+// A or B is nil if the setting only exists in one of the schemes.
+type SettingDiff struct {
+	Subgroup string // subgroup name (from whichever scheme has it)
+	A, B     *PowerSetting
+	ACDiffer bool
+	DCDiffer bool
+}
+
+// diffPowerSchemes compares two schemes setting by setting (matched by subgroup and
+// setting GUID) and returns only the settings with different AC/DC values, in the
+// order of scheme a followed by settings only present in b
+func diffPowerSchemes(a, b *PowerScheme) []SettingDiff {
+	type key struct{ subgroup, setting string }
+	inB := make(map[key]*PowerSetting)
+	for i := range b.Subgroups {
+		for j := range b.Subgroups[i].Settings {
+			inB[key{b.Subgroups[i].GUID, b.Subgroups[i].Settings[j].GUID}] = &b.Subgroups[i].Settings[j]
+		}
+	}
+
+	var diffs []SettingDiff
+	seen := make(map[key]bool)
+	for i := range a.Subgroups {
+		sub := &a.Subgroups[i]
+		for j := range sub.Settings {
+			settingA := &sub.Settings[j]
+			k := key{sub.GUID, settingA.GUID}
+			seen[k] = true
+			settingB, found := inB[k]
+			if !found {
+				diffs = append(diffs, SettingDiff{Subgroup: sub.Name, A: settingA})
+				continue
+			}
+			acDiffer := settingA.HasAC != settingB.HasAC || settingA.AC != settingB.AC
+			dcDiffer := settingA.HasDC != settingB.HasDC || settingA.DC != settingB.DC
+			if acDiffer || dcDiffer {
+				diffs = append(diffs, SettingDiff{Subgroup: sub.Name, A: settingA, B: settingB, ACDiffer: acDiffer, DCDiffer: dcDiffer})
+			}
+		}
+	}
+	for i := range b.Subgroups {
+		sub := &b.Subgroups[i]
+		for j := range sub.Settings {
+			if !seen[key{sub.GUID, sub.Settings[j].GUID}] {
+				diffs = append(diffs, SettingDiff{Subgroup: sub.Name, B: &sub.Settings[j]})
+			}
+		}
+	}
+	return diffs
+}
+
+// loadPowerScheme loads a scheme for comparison. spec is either a scheme GUID, alias
+// or stock scheme name, or a snapshot file (JSON written by "dump -o" or saved
+// powercfg /query output). Scheme identifiers take precedence, so a file named like
+// one in the working directory does not replace the scheme on this machine.
+func loadPowerScheme(spec string, hidden bool) (*PowerScheme, error) {
+	if isSchemeIdentifier(spec) {
+		return queryPowerScheme(spec, hidden)
+	}
+	data, err := os.ReadFile(spec)
+	if os.IsNotExist(err) {
+		// Neither an identifier nor a file: let powercfg report unknown schemes
+		return queryPowerScheme(spec, hidden)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen von %s: %w", spec, err)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		var scheme PowerScheme
+		if err := json.Unmarshal(data, &scheme); err != nil {
+			return nil, fmt.Errorf("Fehler beim Lesen von %s: %w", spec, err)
+		}
+		return &scheme, nil
+	}
+	scheme := parsePowerSchemeQuery(string(data))
+	if scheme == nil {
+		return nil, fmt.Errorf("%s enthält keine powercfg /query-Ausgabe", spec)
+	}
+	return scheme, nil
+}
+
+// isSchemeIdentifier reports whether spec names a scheme on this machine: a GUID,
+// an alias such as SCHEME_CURRENT or the name of a stock scheme
+func isSchemeIdentifier(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	if builtinSchemeGUID(spec) != "" || aliasPattern.MatchString(spec) {
+		return true
+	}
+	m := guidPattern.FindStringSubmatch(spec)
+	return m != nil && m[0] == spec
+}
+
+// savePowerScheme writes a scheme snapshot as JSON for comparison on another machine
+func savePowerScheme(scheme *PowerScheme, path string) error {
+	data, err := json.MarshalIndent(scheme, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scheme: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+var diffHiddenFlag bool
+
+func setupDiffCommand(fs *flag.FlagSet) {
+	fs.BoolVar(&diffHiddenFlag, "hidden", false, "Include settings hidden in the Control Panel")
+}
+
+// runDiffCommand compares two schemes or snapshots. Without arguments the active
+// scheme is compared with Balanced, with one argument the active scheme with it.
+// Exit code: 0 = identical, 1 = differences found, 2 = error.
+func runDiffCommand(args []string) int {
+	specA, specB := "SCHEME_CURRENT", "SCHEME_BALANCED"
+	switch len(args) {
+	case 0:
+	case 1:
+		specB = args[0]
+	default:
+		specA, specB = args[0], args[1]
+	}
+
+	a, err := loadPowerScheme(specA, diffHiddenFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	b, err := loadPowerScheme(specB, diffHiddenFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	diffs := diffPowerSchemes(a, b)
+	showSchemeDiff(a, b, specA, specB, diffs)
+	if len(diffs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// showSchemeDiff prints the differences between two schemes
+func showSchemeDiff(a, b *PowerScheme, specA, specB string, diffs []SettingDiff) {
+	printUTF8ln("=== Vergleich der Energieschemas ===")
+	printUTF8ln("A: %s (%s) [%s]", a.Name, a.GUID, specA)
+	printUTF8ln("B: %s (%s) [%s]", b.Name, b.GUID, specB)
+
+	if len(diffs) == 0 {
+		printUTF8ln("\nKeine Unterschiede gefunden.")
+		return
+	}
+
+	for _, diff := range diffs {
+		switch {
+		case diff.B == nil:
+			printUTF8ln("\n[%s] %s %s", diff.Subgroup, diff.A.Name, formatGUIDAlias(diff.A.GUID, diff.A.Alias))
+			printUTF8ln("  Nur in A vorhanden")
+		case diff.A == nil:
+			printUTF8ln("\n[%s] %s %s", diff.Subgroup, diff.B.Name, formatGUIDAlias(diff.B.GUID, diff.B.Alias))
+			printUTF8ln("  Nur in B vorhanden")
+		default:
+			printUTF8ln("\n[%s] %s %s", diff.Subgroup, diff.A.Name, formatGUIDAlias(diff.A.GUID, diff.A.Alias))
+			if diff.ACDiffer {
+				printUTF8ln("  Netzbetrieb: %s -> %s", formatSettingIndex(diff.A, diff.A.AC, diff.A.HasAC), formatSettingIndex(diff.B, diff.B.AC, diff.B.HasAC))
+			}
+			if diff.DCDiffer {
+				printUTF8ln("  Batterie:    %s -> %s", formatSettingIndex(diff.A, diff.A.DC, diff.A.HasDC), formatSettingIndex(diff.B, diff.B.DC, diff.B.HasDC))
+			}
+		}
+	}
+	printUTF8ln("\n%d unterschiedliche Einstellungen", len(diffs))
+}
+
+// formatSettingIndex formats a current value that may be missing
+func formatSettingIndex(setting *PowerSetting, value uint32, found bool) string {
+	if !found {
+		return "-"
+	}
+	return setting.FormatValue(value)
+}
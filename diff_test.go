@@ -0,0 +1,114 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsSchemeIdentifier(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"SCHEME_CURRENT", true},
+		{"SCHEME_BALANCED", true},
+		{"381b4222-f694-41f0-9685-ff5bb260df2e", true},
+		{"381B4222-F694-41F0-9685-FF5BB260DF2E", true},
+		{"balanced", true},
+		{"High-Performance", true},
+		{"office.json", false},
+		{`snapshots\381b4222-f694-41f0-9685-ff5bb260df2e`, false},
+		{"381b4222-f694-41f0-9685-ff5bb260df2e.json", false},
+		{"scheme_current", false}, // powercfg aliases are upper case
+		{"balanced.txt", false},
+	}
+	for _, tt := range tests {
+		if got := isSchemeIdentifier(tt.spec); got != tt.want {
+			t.Errorf("isSchemeIdentifier(%q) = %t, want %t", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestDiffPowerSchemes(t *testing.T) {
+	setting := func(guid string, ac, dc uint32) PowerSetting {
+		return PowerSetting{GUID: guid, Name: guid, AC: ac, DC: dc, HasAC: true, HasDC: true}
+	}
+	scheme := func(settings ...PowerSetting) *PowerScheme {
+		return &PowerScheme{Subgroups: []PowerSubgroup{{GUID: "sub-sleep", Name: "Sleep", Settings: settings}}}
+	}
+	noDC := setting("rtcwake", 1, 0)
+	noDC.HasDC = false
+
+	tests := []struct {
+		name string
+		a, b *PowerScheme
+		want []string // setting GUID and what differs
+	}{
+		{"identical", scheme(setting("standbyidle", 1800, 900)), scheme(setting("standbyidle", 1800, 900)), nil},
+		{"AC differs", scheme(setting("standbyidle", 1800, 900)), scheme(setting("standbyidle", 0, 900)), []string{"standbyidle AC"}},
+		{"DC differs", scheme(setting("standbyidle", 1800, 900)), scheme(setting("standbyidle", 1800, 600)), []string{"standbyidle DC"}},
+		{"both differ", scheme(setting("standbyidle", 1800, 900)), scheme(setting("standbyidle", 0, 0)), []string{"standbyidle AC DC"}},
+		{"missing value", scheme(setting("rtcwake", 1, 0)), scheme(noDC), []string{"rtcwake DC"}},
+		{"only in A", scheme(setting("standbyidle", 1, 1), setting("hibernateidle", 1, 1)), scheme(setting("standbyidle", 1, 1)), []string{"hibernateidle only A"}},
+		{"only in B, after the settings of A", scheme(setting("standbyidle", 1, 1)), scheme(setting("hibernateidle", 1, 1), setting("standbyidle", 2, 1)),
+			[]string{"standbyidle AC", "hibernateidle only B"}},
+		{"same setting in another subgroup", scheme(setting("standbyidle", 1, 1)),
+			&PowerScheme{Subgroups: []PowerSubgroup{{GUID: "sub-none", Settings: []PowerSetting{setting("standbyidle", 1, 1)}}}},
+			[]string{"standbyidle only A", "standbyidle only B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, diff := range diffPowerSchemes(tt.a, tt.b) {
+				switch {
+				case diff.B == nil:
+					got = append(got, diff.A.GUID+" only A")
+				case diff.A == nil:
+					got = append(got, diff.B.GUID+" only B")
+				default:
+					text := diff.A.GUID
+					if diff.ACDiffer {
+						text += " AC"
+					}
+					if diff.DCDiffer {
+						text += " DC"
+					}
+					got = append(got, text)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diff = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowerSchemeSnapshot(t *testing.T) {
+	scheme := parsePowerSchemeQuery(readFixture(t, "powercfg-query-de.txt"))
+	path := filepath.Join(t.TempDir(), "scheme.json")
+	if err := savePowerScheme(scheme, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadPowerScheme(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, scheme) {
+		t.Errorf("loaded snapshot differs:\n%+v\nsaved\n%+v", loaded, scheme)
+	}
+
+	// Saved powercfg /query output is a snapshot too
+	queried, err := loadPowerScheme(filepath.Join("testdata", "powercfg-query-de.txt"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := diffPowerSchemes(queried, loaded); len(diffs) != 0 {
+		t.Errorf("diff between the snapshots of the same scheme: %+v", diffs)
+	}
+
+	if _, err := loadPowerScheme(filepath.Join("testdata", "waketimers-en.txt"), false); err == nil || !strings.Contains(err.Error(), "keine powercfg /query-Ausgabe") {
+		t.Errorf("file without scheme: err = %v", err)
+	}
+}
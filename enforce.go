@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// defaultEnforceInterval is how often the service compares the state with the profile
+const defaultEnforceInterval = 15 * time.Minute
+
+// enforceRetryAfter is how long drift that re-applying the profile could not correct
+// is left alone, e.g. a Modern Standby change that only takes effect after a restart.
+// Drift that changes in the meantime is handled at once.
+const enforceRetryAfter = 24 * time.Hour
+
+// enforcementRunner checks and re-applies the desired configuration. The service uses
+// profileRunner; the interface keeps the loop free of Windows calls.
+type enforcementRunner interface {
+	Check() ([]Deviation, error)
+	Apply() error
+}
+
+// enforcer periodically checks the configuration and re-applies it on drift
+type enforcer struct {
+	clock    clock
+	runner   enforcementRunner
+	interval time.Duration
+	logf     func(format string, args ...interface{})
+	emit     func(Event) // writes events to the event log
+
+	unresolved      []Deviation // drift left after the last re-apply
+	unresolvedSince time.Time
+}
+
+// EnforceResult is the outcome of one enforcement pass
+type EnforceResult struct {
+	Time       time.Time
+	Deviations []Deviation // drift found before applying
+	Applied    bool
+	Unresolved bool        // the drift was left after the last re-apply and is not retried yet
+	Remaining  []Deviation // drift left after applying
+	Err        error
+}
+
+// runOnce checks the state, re-applies the profile if it drifted and checks again
+func (e *enforcer) runOnce() EnforceResult {
+	result := EnforceResult{Time: e.clock.Now()}
+	result.Deviations, result.Err = e.runner.Check()
+	if result.Err != nil {
+		e.logf("check failed: %v", result.Err)
+		e.emit(errorEvent("check", result.Err))
+		return result
+	}
+	if len(result.Deviations) == 0 {
+		e.unresolved = nil
+		return result
+	}
+	if sameDeviations(result.Deviations, e.unresolved) && result.Time.Sub(e.unresolvedSince) < enforceRetryAfter {
+		result.Unresolved = true
+		return result
+	}
+
+	for _, d := range result.Deviations {
+		e.logf("drift: %s is %s, want %s", d.Item, d.Have, d.Want)
+	}
+	e.emit(driftEvent(eventDriftDetected, result.Deviations))
+	if result.Err = e.runner.Apply(); result.Err != nil {
+		e.logf("re-applying the profile failed: %v", result.Err)
+		e.emit(errorEvent("re-apply profile", result.Err))
+		e.setUnresolved(result.Deviations, result.Time)
+		return result
+	}
+	result.Applied = true
+
+	result.Remaining, result.Err = e.runner.Check()
+	switch {
+	case result.Err != nil:
+		e.logf("check after re-applying failed: %v", result.Err)
+		e.emit(errorEvent("check", result.Err))
+	case len(result.Remaining) == 0:
+		e.logf("profile re-applied, %d deviation(s) corrected", len(result.Deviations))
+		e.emit(driftEvent(eventDriftCorrected, result.Deviations))
+		e.unresolved = nil
+	default:
+		for _, d := range result.Remaining {
+			e.logf("still drifted after re-applying: %s is %s, want %s", d.Item, d.Have, d.Want)
+		}
+		e.emit(driftEvent(eventDriftRemaining, result.Remaining))
+		e.setUnresolved(result.Remaining, result.Time)
+	}
+	return result
+}
+
+// setUnresolved remembers drift that re-applying did not correct, so the next passes
+// do not reconfigure the system and log the same drift again every interval
+func (e *enforcer) setUnresolved(deviations []Deviation, now time.Time) {
+	e.unresolved, e.unresolvedSince = deviations, now
+	e.logf("not re-applying the profile for this drift again before %s unless it changes",
+		now.Add(enforceRetryAfter).Format("2006-01-02 15:04"))
+}
+
+// sameDeviations reports whether two checks found the same drift
+func sameDeviations(a, b []Deviation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, d := range a {
+		if !slices.Contains(b, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run enforces the profile immediately and then every interval until stop is closed.
+// A pass can also be requested through trigger, e.g. after resume.
+func (e *enforcer) Run(stop <-chan struct{}, trigger <-chan struct{}) {
+	e.logf("enforcing profile every %s", e.interval)
+	for {
+		e.runOnce()
+		select {
+		case <-stop:
+			e.logf("stopped")
+			return
+		case <-trigger:
+		case <-e.clock.After(e.interval):
+		}
+	}
+}
+
+// profileRunner checks and applies the profile file on this machine
+type profileRunner struct {
+	path string
+}
+
+func (r profileRunner) Check() ([]Deviation, error) {
+	profile, err := loadProfile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	state, err := querySystemState()
+	if err != nil {
+		return nil, err
+	}
+	return compareProfile(profile, state, time.Now()), nil
+}
+
+func (r profileRunner) Apply() error {
+	profilePath = r.path
+	if err := configurePowerSettings(0); err != nil {
+		return fmt.Errorf("configure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose time only moves through Sleep. After hands the
+// requested timer to the test through timers, so a test knows when the code under
+// test is waiting and decides when the timer fires.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers chan fakeTimer
+}
+
+type fakeTimer struct {
+	d  time.Duration
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, timers: make(chan fakeTimer, 1)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.timers <- fakeTimer{d: d, ch: ch}
+	return ch
+}
+
+// fakeRunner returns the scripted results of Check in order; the last result
+// repeats
+type fakeRunner struct {
+	mu       sync.Mutex
+	checks   [][]Deviation
+	checkErr error
+	applyErr error
+	checked  int
+	applied  int
+}
+
+func (r *fakeRunner) Check() ([]Deviation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := min(r.checked, len(r.checks)-1)
+	r.checked++
+	if r.checkErr != nil {
+		return nil, r.checkErr
+	}
+	return r.checks[i], nil
+}
+
+func (r *fakeRunner) Apply() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.applied++
+	return r.applyErr
+}
+
+func (r *fakeRunner) counts() (checked, applied int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.checked, r.applied
+}
+
+func newTestEnforcer(runner enforcementRunner, c clock) (*enforcer, *[]uint32) {
+	var events []uint32
+	e := &enforcer{
+		clock:    c,
+		runner:   runner,
+		interval: 15 * time.Minute,
+		logf:     func(string, ...interface{}) {},
+		emit:     func(event Event) { events = append(events, event.ID) },
+	}
+	return e, &events
+}
+
+func TestEnforcerRunOnce(t *testing.T) {
+	drift := []Deviation{{Item: "sleep.ac", Want: "30 min", Have: "never"}}
+	tests := []struct {
+		name        string
+		runner      *fakeRunner
+		wantApplied int
+		wantResult  bool // EnforceResult.Applied
+		wantErr     bool
+		wantEvents  []uint32
+	}{
+		{"compliant", &fakeRunner{checks: [][]Deviation{nil}}, 0, false, false, nil},
+		{"drift corrected", &fakeRunner{checks: [][]Deviation{drift, nil}}, 1, true, false, []uint32{eventDriftDetected, eventDriftCorrected}},
+		{"drift remaining", &fakeRunner{checks: [][]Deviation{drift, drift}}, 1, true, false, []uint32{eventDriftDetected, eventDriftRemaining}},
+		{"check fails", &fakeRunner{checks: [][]Deviation{nil}, checkErr: errors.New("powercfg failed")}, 0, false, true, []uint32{eventError}},
+		{"apply fails", &fakeRunner{checks: [][]Deviation{drift}, applyErr: errors.New("access denied")}, 1, false, true, []uint32{eventDriftDetected, eventError}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, events := newTestEnforcer(tt.runner, newFakeClock(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)))
+			result := e.runOnce()
+			if _, applied := tt.runner.counts(); applied != tt.wantApplied {
+				t.Errorf("Apply called %d times, want %d", applied, tt.wantApplied)
+			}
+			if result.Applied != tt.wantResult {
+				t.Errorf("Applied = %t, want %t", result.Applied, tt.wantResult)
+			}
+			if (result.Err != nil) != tt.wantErr {
+				t.Errorf("Err = %v, want error %t", result.Err, tt.wantErr)
+			}
+			if fmt.Sprint(*events) != fmt.Sprint(tt.wantEvents) {
+				t.Errorf("events = %v, want %v", *events, tt.wantEvents)
+			}
+			if !result.Time.Equal(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)) {
+				t.Errorf("Time = %v, want the clock time", result.Time)
+			}
+		})
+	}
+}
+
+func TestEnforcerRun(t *testing.T) {
+	c := newFakeClock(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	runner := &fakeRunner{checks: [][]Deviation{nil}}
+	e, _ := newTestEnforcer(runner, c)
+	stop, trigger, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		e.Run(stop, trigger)
+		close(done)
+	}()
+
+	wait := func() fakeTimer {
+		t.Helper()
+		select {
+		case timer := <-c.timers:
+			return timer
+		case <-time.After(5 * time.Second):
+			t.Fatal("enforcer did not wait for the next interval")
+			return fakeTimer{}
+		}
+	}
+
+	// The first pass runs immediately, then the enforcer waits for the interval
+	timer := wait()
+	if timer.d != 15*time.Minute {
+		t.Errorf("waits %s, want 15m", timer.d)
+	}
+	if checked, _ := runner.counts(); checked != 1 {
+		t.Errorf("checked %d times before the first interval, want 1", checked)
+	}
+
+	// The interval elapses
+	timer.ch <- c.Now()
+	wait()
+	if checked, _ := runner.counts(); checked != 2 {
+		t.Errorf("checked %d times after one interval, want 2", checked)
+	}
+
+	// A trigger (e.g. resume) runs a pass without waiting for the interval
+	trigger <- struct{}{}
+	wait()
+	if checked, _ := runner.counts(); checked != 3 {
+		t.Errorf("checked %d times after the trigger, want 3", checked)
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after stop")
+	}
+	if checked, _ := runner.counts(); checked != 3 {
+		t.Errorf("checked %d times after stop, want 3", checked)
+	}
+}
+
+func TestEnforcerDoesNotRetryUnresolvedDrift(t *testing.T) {
+	restart := []Deviation{{Item: "modern-standby", Want: "off", Have: "on"}}
+	sleep := []Deviation{{Item: "sleep.ac", Want: "30 min", Have: "never"}}
+	c := newFakeClock(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	runner := &fakeRunner{}
+	e, events := newTestEnforcer(runner, c)
+
+	pass := func(checks ...[]Deviation) EnforceResult {
+		runner.checks, runner.checked = checks, 0
+		*events = nil
+		return e.runOnce()
+	}
+
+	// Re-applying does not correct the drift
+	if result := pass(restart, restart); !result.Applied || len(result.Remaining) != 1 {
+		t.Fatalf("first pass: Applied = %t, Remaining = %v", result.Applied, result.Remaining)
+	}
+
+	// The same drift is not re-applied and not reported again
+	c.Sleep(15 * time.Minute)
+	result := pass(restart)
+	if !result.Unresolved || result.Applied || len(*events) != 0 {
+		t.Errorf("same drift: Unresolved = %t, Applied = %t, events = %v", result.Unresolved, result.Applied, *events)
+	}
+
+	// New drift is handled at once
+	both := append(append([]Deviation{}, sleep...), restart...)
+	if result := pass(both, restart); result.Unresolved || !result.Applied {
+		t.Errorf("changed drift: Unresolved = %t, Applied = %t", result.Unresolved, result.Applied)
+	}
+	if result := pass(restart); !result.Unresolved {
+		t.Errorf("drift left after the second re-apply is retried")
+	}
+
+	// After enforceRetryAfter the drift is retried
+	c.Sleep(enforceRetryAfter)
+	if result := pass(restart, restart); result.Unresolved || !result.Applied {
+		t.Errorf("after %s: Unresolved = %t, Applied = %t", enforceRetryAfter, result.Unresolved, result.Applied)
+	}
+
+	// Once compliant, the same drift is handled again when it reappears
+	pass(nil)
+	if result := pass(restart, nil); result.Unresolved || !result.Applied {
+		t.Errorf("drift after compliance: Unresolved = %t, Applied = %t", result.Unresolved, result.Applied)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// eventSourceName is the event source SleepRight writes to the Application log
+const eventSourceName = "SleepRight"
+
+// eventLevel is the severity of an event log entry
+type eventLevel int
+
+const (
+	levelInfo eventLevel = iota
+	levelWarning
+	levelError
+)
+
+// String returns the level as shown in Event Viewer
+func (l eventLevel) String() string {
+	switch l {
+	case levelWarning:
+		return "Warning"
+	case levelError:
+		return "Error"
+	default:
+		return "Information"
+	}
+}
+
+// Event IDs written to the Application log. The IDs are stable: SIEM rules and
+// Event Viewer filters refer to them, so existing IDs must never be renumbered.
+// EventCreate.exe, registered as message file, only formats IDs from 1 to 1000.
+const (
+	eventChangeApplied     uint32 = 100
+	eventChangeFailed      uint32 = 101
+	eventConfigureStarted  uint32 = 200
+	eventConfigureFinished uint32 = 201
+	eventConfigureFailed   uint32 = 202
+	eventDriftDetected     uint32 = 300
+	eventDriftCorrected    uint32 = 301
+	eventDriftRemaining    uint32 = 302
+	eventUnexpectedWake    uint32 = 400
+	eventError             uint32 = 900
+)
+
+// eventDefinition describes an event ID of the catalog
+type eventDefinition struct {
+	ID          uint32
+	Level       eventLevel
+	Description string
+}
+
+// eventCatalog lists all events SleepRight writes, ordered by ID
+var eventCatalog = []eventDefinition{
+	{eventChangeApplied, levelInfo, "A change was applied (audit entry)"},
+	{eventChangeFailed, levelWarning, "A change failed (audit entry)"},
+	{eventConfigureStarted, levelInfo, "Configuration started"},
+	{eventConfigureFinished, levelInfo, "Configuration finished"},
+	{eventConfigureFailed, levelError, "Configuration failed"},
+	{eventDriftDetected, levelWarning, "The configuration drifted from the profile"},
+	{eventDriftCorrected, levelInfo, "The drifted configuration was corrected"},
+	{eventDriftRemaining, levelWarning, "The configuration still deviates after re-applying the profile"},
+	{eventUnexpectedWake, levelWarning, "The computer was woken by an unexpected source"},
+	{eventError, levelError, "An error occurred"},
+}
+
+// findEventDefinition returns the catalog entry of an ID or nil
+func findEventDefinition(id uint32) *eventDefinition {
+	for i := range eventCatalog {
+		if eventCatalog[i].ID == id {
+			return &eventCatalog[i]
+		}
+	}
+	return nil
+}
+
+// Event is an entry for the Application log
+type Event struct {
+	ID      uint32
+	Level   eventLevel
+	Message string
+}
+
+// newEvent formats an event: the catalog description as first line, followed by
+// "key: value" lines so forwarders can parse the payload. fields alternates keys and
+// values; empty values are left out. An ID missing from the catalog is a programming
+// error and panics.
+func newEvent(id uint32, fields ...string) Event {
+	definition := findEventDefinition(id)
+	if definition == nil {
+		panic(fmt.Sprintf("event ID %d is not in the event catalog", id))
+	}
+	lines := []string{definition.Description + "."}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] != "" {
+			lines = append(lines, fields[i]+": "+fields[i+1])
+		}
+	}
+	return Event{ID: definition.ID, Level: definition.Level, Message: strings.Join(lines, "\r\n")}
+}
+
+// auditEvent converts an audit entry into an event
+func auditEvent(entry AuditEntry) Event {
+	id := eventChangeApplied
+	if entry.Result != "ok" {
+		id = eventChangeFailed
+	}
+	return newEvent(id,
+		"Action", entry.Action,
+		"Target", entry.Target,
+		"Previous", entry.Previous,
+		"New", entry.New,
+		"Command", entry.Command,
+		"User", entry.User,
+		"Elevated", fmt.Sprintf("%t", entry.Elevated),
+		"Error", entry.Error)
+}
+
+// configureStartedEvent is written when -configure starts
+func configureStartedEvent(profile string) Event {
+	if profile == "" {
+		profile = "(built-in)"
+	}
+	return newEvent(eventConfigureStarted, "Profile", profile)
+}
+
+// configureFinishedEvent is written when -configure completes
+func configureFinishedEvent() Event {
+	return newEvent(eventConfigureFinished)
+}
+
+// configureFailedEvent is written when -configure stops with an error
+func configureFailedEvent(err error) Event {
+	return newEvent(eventConfigureFailed, "Error", err.Error())
+}
+
+// driftEvent reports deviations from the profile with one line per deviation
+func driftEvent(id uint32, deviations []Deviation) Event {
+	fields := []string{"Deviations", fmt.Sprintf("%d", len(deviations))}
+	for _, d := range deviations {
+		fields = append(fields, d.Item, fmt.Sprintf("%s (want %s)", d.Have, d.Want))
+	}
+	return newEvent(id, fields...)
+}
+
+// unexpectedWakeEvent reports a wake by a source other than user input
+func unexpectedWakeEvent(record WakeRecord) Event {
+	return newEvent(eventUnexpectedWake,
+		"Source", record.Source,
+		"Time", record.Time.Format("2006-01-02 15:04:05"),
+		"Newly armed", strings.Join(record.NewlyArmed, ", "))
+}
+
+// errorEvent reports an error of the given operation
+func errorEvent(operation string, err error) Event {
+	return newEvent(eventError, "Operation", operation, "Error", err.Error())
+}
+
+// expectedWakeKeywords are parts of wake source names that indicate user input
+var expectedWakeKeywords = []string{"keyboard", "tastatur", "mouse", "maus", "power button", "netzschalter", "lid switch", "deckel"}
+
+// isExpectedWakeSource reports whether a wake source is user input
+func isExpectedWakeSource(source string) bool {
+	lower := strings.ToLower(source)
+	for _, keyword := range expectedWakeKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// showEventCatalog prints the event IDs SleepRight writes
+func showEventCatalog() {
+	printUTF8ln("Ereignisse der Quelle %s im Anwendungsprotokoll:", eventSourceName)
+	for _, definition := range eventCatalog {
+		printUTF8ln("  %4d  %-11s %s", definition.ID, definition.Level, definition.Description)
+	}
+}
+
+// runEventLogCommand lists the event IDs or installs/removes the event source
+func runEventLogCommand(args []string) int {
+	switch {
+	case len(args) == 0:
+		showEventCatalog()
+		if eventSourceInstalled() {
+			printUTF8ln("Ereignisquelle installiert: Ja")
+		} else {
+			printUTF8ln("Ereignisquelle installiert: Nein (\"SleepRight eventlog on\")")
+		}
+		return 0
+	case len(args) == 1 && (args[0] == "on" || args[0] == "off"):
+		if err := setEventSource(args[0] == "on"); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight eventlog [on|off]\n")
+		return 1
+	}
+}
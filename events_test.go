@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEventCatalog(t *testing.T) {
+	for i, definition := range eventCatalog {
+		// EventCreate.exe only formats IDs from 1 to 1000
+		if definition.ID < 1 || definition.ID > 1000 {
+			t.Errorf("event %d is outside of 1..1000", definition.ID)
+		}
+		if definition.Description == "" {
+			t.Errorf("event %d has no description", definition.ID)
+		}
+		// Ascending order also rules out duplicates
+		if i > 0 && definition.ID <= eventCatalog[i-1].ID {
+			t.Errorf("event %d follows %d, IDs must be unique and ascending", definition.ID, eventCatalog[i-1].ID)
+		}
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     uint32
+		fields []string
+		want   Event
+	}{
+		{"description only", eventConfigureFinished, nil,
+			Event{eventConfigureFinished, levelInfo, "Configuration finished."}},
+		{"fields", eventConfigureStarted, []string{"Profile", "office.json", "User", `PC\jan`},
+			Event{eventConfigureStarted, levelInfo, "Configuration started.\r\nProfile: office.json\r\nUser: PC\\jan"}},
+		{"empty values left out", eventError, []string{"Operation", "", "Error", "access denied"},
+			Event{eventError, levelError, "An error occurred.\r\nError: access denied"}},
+		{"key without value ignored", eventDriftDetected, []string{"Deviations"},
+			Event{eventDriftDetected, levelWarning, "The configuration drifted from the profile."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newEvent(tt.id, tt.fields...); got != tt.want {
+				t.Errorf("newEvent = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEventUnknownID(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("newEvent did not panic for an ID missing from the catalog")
+		}
+	}()
+	newEvent(999)
+}
+
+func TestDriftEvent(t *testing.T) {
+	deviations := []Deviation{
+		{Item: "sleep.ac", Want: "30 min", Have: "never"},
+		{Item: "wake-timers.dc", Want: "disabled", Have: "enabled"},
+	}
+	got := driftEvent(eventDriftRemaining, deviations)
+	want := Event{eventDriftRemaining, levelWarning, strings.Join([]string{
+		"The configuration still deviates after re-applying the profile.",
+		"Deviations: 2",
+		"sleep.ac: never (want 30 min)",
+		"wake-timers.dc: enabled (want disabled)",
+	}, "\r\n")}
+	if got != want {
+		t.Errorf("driftEvent = %+v, want %+v", got, want)
+	}
+}
+
+func TestAuditEvent(t *testing.T) {
+	entry := AuditEntry{
+		User:     `PC\jan`,
+		Elevated: true,
+		Action:   "device-wake",
+		Target:   "HID Keyboard Device",
+		Command:  `powercfg /devicedisablewake "HID Keyboard Device"`,
+		Previous: "armed",
+		New:      "disarmed",
+		Result:   "ok",
+	}
+	got := auditEvent(entry)
+	want := Event{eventChangeApplied, levelInfo, strings.Join([]string{
+		"A change was applied (audit entry).",
+		"Action: device-wake",
+		"Target: HID Keyboard Device",
+		"Previous: armed",
+		"New: disarmed",
+		`Command: powercfg /devicedisablewake "HID Keyboard Device"`,
+		`User: PC\jan`,
+		"Elevated: true",
+	}, "\r\n")}
+	if got != want {
+		t.Errorf("auditEvent = %+v, want %+v", got, want)
+	}
+
+	entry.Result, entry.Error, entry.Previous = "failed", "access denied", ""
+	got = auditEvent(entry)
+	if got.ID != eventChangeFailed || got.Level != levelWarning {
+		t.Errorf("failed change: ID %d level %s, want %d Warning", got.ID, got.Level, eventChangeFailed)
+	}
+	if !strings.HasSuffix(got.Message, "\r\nError: access denied") || strings.Contains(got.Message, "Previous:") {
+		t.Errorf("failed change message = %q", got.Message)
+	}
+}
+
+func TestErrorEvent(t *testing.T) {
+	got := errorEvent("check", errors.New("powercfg failed"))
+	want := Event{eventError, levelError, "An error occurred.\r\nOperation: check\r\nError: powercfg failed"}
+	if got != want {
+		t.Errorf("errorEvent = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventSourceInstalled reports whether the SleepRight event source is registered
+func eventSourceInstalled() bool {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\EventLog\Application\`+eventSourceName, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	key.Close()
+	return true
+}
+
+// writeEvent writes an event to the Application log if the SleepRight event source
+// is installed ("SleepRight eventlog on")
+func writeEvent(event Event) {
+	if !eventSourceInstalled() {
+		return
+	}
+	log, err := eventlog.Open(eventSourceName)
+	if err != nil {
+		return
+	}
+	defer log.Close()
+
+	switch event.Level {
+	case levelError:
+		log.Error(event.ID, event.Message)
+	case levelWarning:
+		log.Warning(event.ID, event.Message)
+	default:
+		log.Info(event.ID, event.Message)
+	}
+}
+
+// setEventSource registers or removes the SleepRight event source
+func setEventSource(enabled bool) error {
+	if !enabled {
+		if err := eventlog.Remove(eventSourceName); err != nil {
+			return fmt.Errorf("failed to remove event source %s: %w", eventSourceName, err)
+		}
+		fmt.Printf("Event source %s removed.\n", eventSourceName)
+		return nil
+	}
+	if eventSourceInstalled() {
+		fmt.Printf("Event source %s is already installed.\n", eventSourceName)
+		return nil
+	}
+	if err := eventlog.InstallAsEventCreate(eventSourceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		return fmt.Errorf("failed to install event source %s: %w", eventSourceName, err)
+	}
+	fmt.Printf("Event source %s installed, events are written to the Application log.\n", eventSourceName)
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import "fmt"
+
+// Fast Startup ("Schnellstart") is controlled by HiberbootEnabled: with it enabled,
+// "Shut down" only logs off and hibernates the kernel session
+const (
+	sessionManagerPowerKey = `SYSTEM\CurrentControlSet\Control\Session Manager\Power`
+	hiberbootEnabledValue  = "HiberbootEnabled"
+)
+
+// FastStartupState is the state of Fast Startup
+type FastStartupState struct {
+	Enabled bool
+	Found   bool // HiberbootEnabled exists
+}
+
+// queryFastStartup reads the Fast Startup state from the registry
+func queryFastStartup(reg registryStore) (FastStartupState, error) {
+	value, found, err := reg.GetDWORD(sessionManagerPowerKey, hiberbootEnabledValue)
+	if err != nil {
+		return FastStartupState{}, err
+	}
+	return FastStartupState{Enabled: found && value != 0, Found: found}, nil
+}
+
+// setFastStartup enables or disables Fast Startup. It returns whether the value was
+// changed.
+func setFastStartup(reg registryStore, enabled bool) (bool, error) {
+	state, err := queryFastStartup(reg)
+	if err != nil {
+		return false, err
+	}
+	if state.Found && state.Enabled == enabled {
+		return false, nil
+	}
+	var value uint32
+	if enabled {
+		value = 1
+	}
+	if err := reg.SetDWORD(sessionManagerPowerKey, hiberbootEnabledValue, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// showFastStartup prints the Fast Startup state
+func showFastStartup() error {
+	state, err := queryFastStartup(systemRegistry)
+	if err != nil {
+		return err
+	}
+
+	printUTF8ln("\nSchnellstart (Fast Startup):")
+	switch {
+	case !state.Found:
+		printUTF8ln("  Nicht konfiguriert (%s fehlt)", hiberbootEnabledValue)
+	case state.Enabled:
+		printUTF8ln("  Aktiviert")
+		printUTF8ln("  Hinweis: Beim Herunterfahren wird der Kernel in den Ruhezustand versetzt statt vollständig beendet.")
+		printUTF8ln("           Das kann zu unerwartetem Aufwachen und Treiberproblemen nach dem Start führen.")
+	default:
+		printUTF8ln("  Deaktiviert")
+	}
+	return nil
+}
+
+// configureFastStartup applies the fast-startup profile key ("on" or "off")
+func configureFastStartup(mode string) error {
+	fmt.Printf("Configuring Fast Startup (%s)...\n", mode)
+	changed, err := setFastStartup(systemRegistry, mode == "on")
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("  Fast Startup already %s.\n", mode)
+		return skipped("already %s", mode)
+	}
+	fmt.Printf("  Fast Startup set to %s.\n", mode)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+const hiberbootPath = sessionManagerPowerKey + `\` + hiberbootEnabledValue
+
+func TestQueryFastStartup(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]uint32
+		getErr  error
+		want    FastStartupState
+		wantErr bool
+	}{
+		{"missing", nil, nil, FastStartupState{}, false},
+		{"disabled", map[string]uint32{hiberbootPath: 0}, nil, FastStartupState{Found: true}, false},
+		{"enabled", map[string]uint32{hiberbootPath: 1}, nil, FastStartupState{Enabled: true, Found: true}, false},
+		{"read error", nil, errAccessDenied, FastStartupState{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newMemoryRegistry()
+			for key, value := range tt.values {
+				reg.values[key] = value
+			}
+			reg.getErr = tt.getErr
+			got, err := queryFastStartup(reg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("state = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFastStartup(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      map[string]uint32
+		enable      bool
+		setErr      error
+		wantChanged bool
+		wantValue   uint32
+		wantFound   bool
+		wantErr     bool
+	}{
+		{"enable missing", nil, true, nil, true, 1, true, false},
+		{"disable missing", nil, false, nil, true, 0, true, false},
+		{"disable enabled", map[string]uint32{hiberbootPath: 1}, false, nil, true, 0, true, false},
+		{"enable disabled", map[string]uint32{hiberbootPath: 0}, true, nil, true, 1, true, false},
+		{"already disabled", map[string]uint32{hiberbootPath: 0}, false, nil, false, 0, true, false},
+		{"already enabled", map[string]uint32{hiberbootPath: 1}, true, nil, false, 1, true, false},
+		{"write error", map[string]uint32{hiberbootPath: 1}, false, errAccessDenied, false, 1, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newMemoryRegistry()
+			for key, value := range tt.values {
+				reg.values[key] = value
+			}
+			reg.setErr = tt.setErr
+			changed, err := setFastStartup(reg, tt.enable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errAccessDenied) {
+				t.Errorf("err = %v, want the registry error", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %t, want %t", changed, tt.wantChanged)
+			}
+			if !tt.wantChanged && reg.writes != 0 {
+				t.Errorf("%d registry writes without a change", reg.writes)
+			}
+			value, found := reg.values[hiberbootPath]
+			if found != tt.wantFound || value != tt.wantValue {
+				t.Errorf("HiberbootEnabled = %d (found %t), want %d (found %t)", value, found, tt.wantValue, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// powerControlKey holds the system wide power configuration
+const powerControlKey = `SYSTEM\CurrentControlSet\Control\Power`
+
+// SleepStates is the parsed output of "powercfg /a"
+type SleepStates struct {
+	Available   []string
+	Unavailable []UnavailableSleepState
+}
+
+// UnavailableSleepState is a sleep state that is not available with the reasons
+// reported by powercfg
+type UnavailableSleepState struct {
+	Name    string
+	Reasons []string
+}
+
+// parseSleepStates parses "powercfg /a" output. The (localized) section headers are
+// not indented, states are indented once and reasons deeper:
+//
+//	The following sleep states are available on this system:
+//	    Standby (S3)
+//	    Hibernate
+//
+//	The following sleep states are not available on this system:
+//	    Standby (S1)
+//	        The system firmware does not support this standby state.
+//
+// The first section lists the available states, all following ones unavailable states.
+func parseSleepStates(output string) SleepStates {
+	var states SleepStates
+	section := 0
+	stateIndent := -1
+	for _, rawLine := range strings.Split(decodeCP1252(output), "\n") {
+		line := strings.TrimRight(rawLine, "\r ")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == 0 {
+			section++
+			continue
+		}
+		if stateIndent < 0 {
+			stateIndent = indent
+		}
+		switch {
+		case section <= 1 && indent <= stateIndent:
+			states.Available = append(states.Available, trimmed)
+		case indent <= stateIndent:
+			states.Unavailable = append(states.Unavailable, UnavailableSleepState{Name: trimmed})
+		case section > 1 && len(states.Unavailable) > 0:
+			last := &states.Unavailable[len(states.Unavailable)-1]
+			last.Reasons = append(last.Reasons, trimmed)
+		}
+	}
+	return states
+}
+
+// hibernateStateNames are the names of the hibernate state in powercfg /a output
+var hibernateStateNames = []string{"Hibernate", "Ruhezustand"}
+
+// HibernateAvailable reports whether hibernation is listed as available
+func (s SleepStates) HibernateAvailable() bool {
+	for _, state := range s.Available {
+		for _, name := range hibernateStateNames {
+			if strings.EqualFold(state, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HibernateReasons returns the reasons given for hibernation not being available
+func (s SleepStates) HibernateReasons() []string {
+	for _, unavailable := range s.Unavailable {
+		for _, name := range hibernateStateNames {
+			if strings.EqualFold(unavailable.Name, name) {
+				return unavailable.Reasons
+			}
+		}
+	}
+	return nil
+}
+
+// HibernationState describes the hibernation feature and the hibernate file
+type HibernationState struct {
+	Available bool // hibernation can be used (powercfg /a)
+	Reasons   []string
+	FileSize  int64  // size of hiberfil.sys, 0 if it does not exist
+	FileType  string // "full", "reduced" or "" if unknown
+}
+
+// queryHibernationState collects the hibernation state from powercfg /a, the
+// hibernate file and the HiberFileType registry value
+func queryHibernationState() (*HibernationState, error) {
+	output, err := runCommandWithEncoding("powercfg", "/a")
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Ausführen von powercfg /a: %w", err)
+	}
+	states := parseSleepStates(output)
+	state := &HibernationState{Available: states.HibernateAvailable(), Reasons: states.HibernateReasons()}
+
+	if info, err := os.Stat(hiberFilePath()); err == nil {
+		state.FileSize = info.Size()
+	}
+
+	if value, found, err := systemRegistry.GetDWORD(powerControlKey, "HiberFileType"); err == nil && found {
+		switch value {
+		case 1:
+			state.FileType = "reduced"
+		case 2:
+			state.FileType = "full"
+		}
+	}
+	return state, nil
+}
+
+// hiberFilePath returns the path of the hibernate file on the system drive
+func hiberFilePath() string {
+	drive := os.Getenv("SystemDrive")
+	if drive == "" {
+		drive = "C:"
+	}
+	return filepath.Join(drive+`\`, "hiberfil.sys")
+}
+
+// showHibernationState prints whether hibernation is available and the hibernate file
+func showHibernationState() error {
+	state, err := queryHibernationState()
+	if err != nil {
+		return err
+	}
+
+	printUTF8ln("\nRuhezustand (Hibernate):")
+	if state.Available {
+		printUTF8ln("  Verfügbar: Ja")
+	} else {
+		printUTF8ln("  Verfügbar: Nein")
+		for _, reason := range state.Reasons {
+			printUTF8ln("    %s", reason)
+		}
+	}
+	if state.FileSize > 0 {
+		printUTF8ln("  Ruhezustandsdatei: %s (%s)", formatFileSize(state.FileSize), formatHiberFileType(state.FileType))
+	} else {
+		printUTF8ln("  Ruhezustandsdatei: nicht vorhanden")
+	}
+	if state.FileType == "reduced" {
+		printUTF8ln("  Hinweis: Eine reduzierte Ruhezustandsdatei reicht nur für den Schnellstart, nicht für den Ruhezustand.")
+	}
+	return nil
+}
+
+// formatHiberFileType returns the German name of the hibernate file type
+func formatHiberFileType(fileType string) string {
+	switch fileType {
+	case "full":
+		return "vollständig"
+	case "reduced":
+		return "reduziert"
+	default:
+		return "Typ unbekannt"
+	}
+}
+
+// formatFileSize formats a size in bytes as GB/MB
+func formatFileSize(size int64) string {
+	const mb = 1024 * 1024
+	if size >= 1024*mb {
+		return strings.Replace(fmt.Sprintf("%.1f GB", float64(size)/(1024*mb)), ".", ",", 1)
+	}
+	return fmt.Sprintf("%d MB", size/mb)
+}
+
+// configureHibernation enables or disables hibernation or sets the hibernate file
+// type. mode is one of on, off, full or reduced; full and reduced also enable it.
+func configureHibernation(mode string) error {
+	fmt.Printf("Configuring hibernation (%s)...\n", mode)
+
+	var args []string
+	switch mode {
+	case "on", "off":
+		args = []string{"/h", mode}
+	case "full", "reduced":
+		// The type can only be set while hibernation is enabled
+		if err := runAudited(auditChange{Action: "hibernation", New: "on"}, "powercfg", "/h", "on"); err != nil {
+			return fmt.Errorf("failed to enable hibernation: %w", err)
+		}
+		args = []string{"/h", "/type", mode}
+	default:
+		return fmt.Errorf("unknown hibernation mode %q (use on, off, full or reduced)", mode)
+	}
+	if err := runAudited(auditChange{Action: "hibernation", New: mode}, "powercfg", args...); err != nil {
+		return fmt.Errorf("failed to run powercfg %s: %w", strings.Join(args, " "), err)
+	}
+
+	fmt.Printf("  Hibernation set to %s.\n", mode)
+	return nil
+}
+
+// checkHibernateTimeout warns if the profile sets a hibernate timeout that cannot take
+// effect because hibernation is disabled or the hibernate file is reduced
+func checkHibernateTimeout(profile *Profile) {
+	if !profile.HibernateTimeoutSet() {
+		return
+	}
+	state, err := queryHibernationState()
+	if err != nil {
+		if verboseFlag {
+			fmt.Printf("  Warning: Could not check hibernation state: %v\n", err)
+		}
+		return
+	}
+	switch {
+	case state.FileType == "reduced" && state.FileSize > 0:
+		fmt.Println("  Warning: A hibernate timeout is configured, but the hibernate file is reduced and only supports Fast Startup.")
+		fmt.Println("           The timeout has no effect. Add \"hibernation: full\" to the profile to enable hibernation.")
+	case !state.Available:
+		fmt.Println("  Warning: A hibernate timeout is configured, but hibernation is not available on this system.")
+		fmt.Println("           The timeout has no effect. Add \"hibernation: on\" to the profile to enable it.")
+	}
+}
+
+// runHibernationCommand shows the hibernation state or changes it
+func runHibernationCommand(args []string) int {
+	if len(args) == 0 {
+		if err := showHibernationState(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if err := configureHibernation(strings.ToLower(args[0])); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
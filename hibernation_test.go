@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSleepStates(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		available   []string
+		unavailable []string
+		hibernate   bool
+		reasons     []string
+		standby     ModernStandbyState
+	}{
+		{
+			fixture:     "powercfg-a-en-s0.txt",
+			available:   []string{"Standby (S0 Low Power Idle) Network Connected"},
+			unavailable: []string{"Standby (S1)", "Standby (S2)", "Standby (S3)", "Hibernate", "Hybrid Sleep", "Fast Startup"},
+			reasons:     []string{"Hibernation has not been enabled."},
+			// S3 is only disabled because of S0, the firmware supports it
+			standby: ModernStandbyState{S0Available: true, S3Firmware: true},
+		},
+		{
+			fixture:     "powercfg-a-en-s3.txt",
+			available:   []string{"Standby (S3)", "Hibernate", "Hybrid Sleep", "Fast Startup"},
+			unavailable: []string{"Standby (S1)", "Standby (S2)", "Standby (S0 Low Power Idle)"},
+			hibernate:   true,
+			standby:     ModernStandbyState{S3Available: true, S3Firmware: true},
+		},
+		{
+			fixture:     "powercfg-a-de-s0.txt",
+			available:   []string{"Standby (S0 Niedriger Energiemodus im Leerlauf) Netzwerk verbunden", "Ruhezustand", "Schnellstart"},
+			unavailable: []string{"Standby (S1)", "Standby (S2)", "Standby (S3)", "Hybrider Standbymodus"},
+			hibernate:   true,
+			// The firmware does not support S3: Modern Standby must not be disabled
+			standby: ModernStandbyState{S0Available: true},
+		},
+		{
+			fixture:     "powercfg-a-de-s3.txt",
+			available:   []string{"Standby (S3)"},
+			unavailable: []string{"Standby (S1)", "Standby (S2)", "Ruhezustand", "Standby (S0 Niedriger Energiemodus im Leerlauf)", "Hybrider Standbymodus", "Schnellstart"},
+			reasons:     []string{"Der Ruhezustand wurde nicht aktiviert."},
+			standby:     ModernStandbyState{S3Available: true, S3Firmware: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			states := parseSleepStates(readFixture(t, tt.fixture))
+			if !reflect.DeepEqual(states.Available, tt.available) {
+				t.Errorf("Available = %q, want %q", states.Available, tt.available)
+			}
+			var unavailable []string
+			for _, state := range states.Unavailable {
+				unavailable = append(unavailable, state.Name)
+				if len(state.Reasons) == 0 {
+					t.Errorf("%s has no reasons", state.Name)
+				}
+			}
+			if !reflect.DeepEqual(unavailable, tt.unavailable) {
+				t.Errorf("Unavailable = %q, want %q", unavailable, tt.unavailable)
+			}
+			if got := states.HibernateAvailable(); got != tt.hibernate {
+				t.Errorf("HibernateAvailable = %t, want %t", got, tt.hibernate)
+			}
+			if got := states.HibernateReasons(); !reflect.DeepEqual(got, tt.reasons) {
+				t.Errorf("HibernateReasons = %q, want %q", got, tt.reasons)
+			}
+			if got := modernStandbyFromSleepStates(states); got != tt.standby {
+				t.Errorf("modernStandbyFromSleepStates = %+v, want %+v", got, tt.standby)
+			}
+		})
+	}
+}
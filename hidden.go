@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// SettingRef is a setting together with its subgroup
+type SettingRef struct {
+	Subgroup *PowerSubgroup
+	Setting  *PowerSetting
+}
+
+// findHiddenSettings returns the settings of all (powercfg /qh) that are missing in
+// visible (powercfg /query), i.e. the settings hidden in the Control Panel
+func findHiddenSettings(visible, all *PowerScheme) []SettingRef {
+	var hidden []SettingRef
+	for i := range all.Subgroups {
+		sub := &all.Subgroups[i]
+		for j := range sub.Settings {
+			if _, setting := visible.Find(sub.GUID, sub.Settings[j].GUID); setting == nil {
+				hidden = append(hidden, SettingRef{Subgroup: sub, Setting: &sub.Settings[j]})
+			}
+		}
+	}
+	return hidden
+}
+
+// queryHiddenSettings queries the active scheme with and without hidden settings
+func queryHiddenSettings() ([]SettingRef, error) {
+	visible, err := queryPowerScheme("", false)
+	if err != nil {
+		return nil, err
+	}
+	all, err := queryPowerScheme("", true)
+	if err != nil {
+		return nil, err
+	}
+	return findHiddenSettings(visible, all), nil
+}
+
+// showHiddenSettings prints the hidden settings of the active scheme with their values
+func showHiddenSettings() error {
+	hidden, err := queryHiddenSettings()
+	if err != nil {
+		return err
+	}
+
+	printUTF8ln("\nIn der Systemsteuerung ausgeblendete Einstellungen: %d", len(hidden))
+	for _, ref := range hidden {
+		setting := ref.Setting
+		printUTF8ln("  [%s] %s %s", ref.Subgroup.Name, setting.Name, formatGUIDAlias(setting.GUID, setting.Alias))
+		printUTF8ln("    Netzbetrieb: %s | Batterie: %s",
+			formatSettingIndex(setting, setting.AC, setting.HasAC), formatSettingIndex(setting, setting.DC, setting.HasDC))
+	}
+	return nil
+}
+
+// runHiddenCommand lists the settings hidden in the Control Panel
+func runHiddenCommand(args []string) int {
+	if err := showHiddenSettings(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runUnhideCommand makes settings visible in the Control Panel
+func runUnhideCommand(args []string) int {
+	return setSettingsHidden(args, false)
+}
+
+// runHideCommand hides settings in the Control Panel again
+func runHideCommand(args []string) int {
+	return setSettingsHidden(args, true)
+}
+
+// setSettingsHidden sets or clears the ATTRIB_HIDE attribute of the given settings
+// (catalog name, GUID or alias). Only the visibility changes, not the values.
+func setSettingsHidden(args []string, hide bool) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no setting given\n")
+		return 1
+	}
+	scheme, err := queryPowerScheme("", true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	attribute, state := "-ATTRIB_HIDE", "visible"
+	if hide {
+		attribute, state = "+ATTRIB_HIDE", "hidden"
+	}
+	exitCode := 0
+	for _, name := range args {
+		_, subgroup, setting, err := resolveSetting(scheme, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			exitCode = 1
+			continue
+		}
+		change := auditChange{Action: "attribute", Target: setting.GUID, New: state}
+		if err := runAudited(change, "powercfg", "-attributes", subgroup.GUID, setting.GUID, attribute); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to change attributes of %s: %v\n", name, err)
+			exitCode = 1
+			continue
+		}
+		printUTF8ln("%s is now %s in the Control Panel.", setting.Name, state)
+	}
+	return exitCode
+}
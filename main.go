@@ -20,11 +20,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
-	"syscall"
 	"time"
-
-	"github.com/Microsoft/go-winio"
-	"golang.org/x/sys/windows"
 )
 
 var (
@@ -35,6 +31,11 @@ var (
 	verboseFlag   bool
 	debugFlag     bool
 	versionFlag   bool
+	timelineFlag  bool
+	timelineDays  int
+	timelineWidth int
+	formatFlag    string
+	profilePath   string
 	childModeFlag string // Pipe name for child mode (elevated instance)
 	stdOutWriter  *os.File
 	stdErrWriter  *os.File
@@ -49,6 +50,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Subcommands (e.g. "SleepRight report") have their own flags
+	if len(os.Args) > 1 {
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			runCommand(cmd, os.Args[2:])
+		}
+	}
+
 	// Parse command line flags first (before elevation check)
 	flag.BoolVar(&infoFlag, "info", false, "Show wake events and current power settings (summary)")
 	flag.BoolVar(&infoFlag, "i", false, "Show wake events and current power settings (summary, short)")
@@ -57,6 +65,11 @@ func main() {
 	flag.BoolVar(&configureFlag, "c", false, "Configure power settings (short)")
 	flag.IntVar(&waitMinutes, "wait", 0, "Set hibernate timeout in minutes")
 	flag.IntVar(&waitMinutes, "w", 0, "Set hibernate timeout in minutes (short)")
+	flag.StringVar(&profilePath, "profile", "", "Profile file with the settings applied by -configure (default: built-in profile)")
+	flag.BoolVar(&timelineFlag, "timeline", false, "Show a sleep timeline chart with -info")
+	flag.IntVar(&timelineDays, "days", 7, "Number of days shown in the sleep timeline")
+	flag.IntVar(&timelineWidth, "width", 0, "Console width for the sleep timeline (default: detect)")
+	flag.StringVar(&formatFlag, "format", "text", "Output format for -info/-info-full: text or markdown")
 	flag.BoolVar(&verboseFlag, "verbose", false, "Verbose output")
 	flag.BoolVar(&verboseFlag, "v", false, "Verbose output (short)")
 	flag.BoolVar(&debugFlag, "debug", false, "Debug mode: show all external command calls")
@@ -72,6 +85,11 @@ func main() {
 		defer CloseChildMode()
 	}
 
+	if formatFlag != "text" && formatFlag != "markdown" {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format %q (use text or markdown)\n", formatFlag)
+		os.Exit(1)
+	}
+
 	// Request administrator privileges if needed (for configure or info operations)
 	if configureFlag || infoFlag || infoFullFlag {
 		if !isAdmin() {
@@ -101,7 +119,12 @@ func main() {
 
 	// Execute requested actions
 	var exitCode int = 0
-	if infoFlag || infoFullFlag {
+	if (infoFlag || infoFullFlag) && formatFlag == "markdown" {
+		if err := showInfoMarkdown(infoFullFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Fehler beim Anzeigen der Informationen: %v\n", err)
+			exitCode = 1
+		}
+	} else if infoFlag || infoFullFlag {
 		if err := showInfo(infoFullFlag); err != nil {
 			fmt.Fprintf(os.Stderr, "Fehler beim Anzeigen der Informationen: %v\n", err)
 			exitCode = 1
@@ -127,7 +150,7 @@ func main() {
 // runAsChild runs in child mode (elevated instance) and redirects output to pipe
 func runAsChild(pipeName string) error {
 	// Connect to the named pipe created by the parent process
-	pipe, err := winio.DialPipe(pipeName, nil)
+	pipe, err := dialPipe(pipeName)
 	if err != nil {
 		return fmt.Errorf("failed to connect to pipe: %w", err)
 	}
@@ -232,7 +255,7 @@ func runAsAdminWithPipe() error {
 	pipeName := fmt.Sprintf(`\\.\pipe\SleepRight_%d`, os.Getpid())
 
 	// Create the named pipe
-	listener, err := winio.ListenPipe(pipeName, nil)
+	listener, err := listenPipe(pipeName)
 	if err != nil {
 		return fmt.Errorf("failed to create pipe: %w", err)
 	}
@@ -366,8 +389,22 @@ func runAsAdminWithPipe() error {
 		}
 	}
 
+	// Flags of a subcommand must come before its positional arguments, so the
+	// internal flags are inserted right after the subcommand name
+	var internalArgs []string
+
+	// The elevated instance runs in its own console, so pass on our console width
+	if timelineFlag && timelineWidth == 0 {
+		internalArgs = append(internalArgs, "-width", fmt.Sprintf("%d", consoleWidth()))
+	}
+
 	// Add -child-mode with pipe name
-	filteredArgs = append(filteredArgs, "-child-mode", pipeName)
+	internalArgs = append(internalArgs, "-child-mode", pipeName)
+	if len(filteredArgs) > 0 && findCommand(filteredArgs[0]) != nil {
+		filteredArgs = append(filteredArgs[:1], append(internalArgs, filteredArgs[1:]...)...)
+	} else {
+		filteredArgs = append(filteredArgs, internalArgs...)
+	}
 
 	// Build argument string
 	argsStr := ""
@@ -382,15 +419,10 @@ func runAsAdminWithPipe() error {
 		}
 	}
 
-	// Use ShellExecute to run as administrator (hidden window)
-	verbPtr, _ := syscall.UTF16PtrFromString("runas")
-	exePtr, _ := syscall.UTF16PtrFromString(exe)
-	argsPtr, _ := syscall.UTF16PtrFromString(argsStr)
-
-	// showCmd := int32(0) // SW_HIDE - hide the window
-	showCmd := int32(1) // SW_NORMAL - show window for debugging
+	// Elevated processes start in the system directory; keep ours so relative paths work
+	cwd, _ := os.Getwd()
 
-	err = windows.ShellExecute(0, verbPtr, exePtr, argsPtr, nil, showCmd)
+	err = runElevated(exe, argsStr, cwd)
 	if err != nil {
 		return fmt.Errorf("failed to execute as administrator: %w", err)
 	}
@@ -429,11 +461,21 @@ func runAsAdminWithPipe() error {
 }
 
 func showUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: SleepRight [OPTIONS]\n\n")
+	fmt.Fprintf(os.Stderr, "Usage: SleepRight [OPTIONS]\n")
+	fmt.Fprintf(os.Stderr, "       SleepRight <COMMAND> [OPTIONS] [ARGUMENTS]\n\n")
+	fmt.Fprintf(os.Stderr, "COMMANDS:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "OPTIONS:\n")
 	fmt.Fprintf(os.Stderr, "  -info, -i              Show wake events and current power settings\n")
 	fmt.Fprintf(os.Stderr, "  -configure, -c         Configure power settings\n")
 	fmt.Fprintf(os.Stderr, "  -wait, -w <minutes>    Set hibernate timeout in minutes\n")
+	fmt.Fprintf(os.Stderr, "  -profile <file>        Profile with the settings applied by -configure\n")
+	fmt.Fprintf(os.Stderr, "  -format <text|markdown> Output format for -info/-info-full\n")
+	fmt.Fprintf(os.Stderr, "  -timeline              Show a sleep timeline chart (with -info)\n")
+	fmt.Fprintf(os.Stderr, "  -days <n>              Days shown in the sleep timeline (default 7)\n")
 	fmt.Fprintf(os.Stderr, "  -verbose, -v           Verbose output\n")
 	fmt.Fprintf(os.Stderr, "  --version              Show version and exit\n")
 	fmt.Fprintf(os.Stderr, "\n")
@@ -441,6 +483,11 @@ func showUsage() {
 	fmt.Fprintf(os.Stderr, "  SleepRight -info                    # Show current settings\n")
 	fmt.Fprintf(os.Stderr, "  SleepRight -configure               # Configure power settings\n")
 	fmt.Fprintf(os.Stderr, "  SleepRight -configure -w 60         # Configure with 60 min before hibernate\n")
+	fmt.Fprintf(os.Stderr, "  SleepRight -configure -profile office.txt # Configure with the settings of a profile\n")
+	fmt.Fprintf(os.Stderr, "  SleepRight -info -timeline -days 14 # Show a sleep chart of the last 14 days\n")
+	fmt.Fprintf(os.Stderr, "  SleepRight -info -format markdown   # Show settings as Markdown for a wiki\n")
+	fmt.Fprintf(os.Stderr, "  SleepRight report -o report.html    # Write an HTML report for a ticket\n")
+	fmt.Fprintf(os.Stderr, "  SleepRight set sleep ac=1h dc=15m   # Sleep after 1 hour on AC, 15 minutes on battery\n")
 }
 
 func showInfo(full bool) error {
@@ -457,31 +504,66 @@ func showInfo(full bool) error {
 	return nil
 }
 
-func configurePowerSettings(hibernateMinutes int) error {
+func configurePowerSettings(hibernateMinutes int) (err error) {
 	fmt.Println("=== Configuring Power Settings ===")
+	writeEvent(configureStartedEvent(profilePath))
+	defer func() {
+		if err != nil {
+			writeEvent(configureFailedEvent(err))
+		} else {
+			writeEvent(configureFinishedEvent())
+		}
+	}()
 
-	if err := configureWakeDevices(); err != nil {
-		return fmt.Errorf("failed to configure wake devices: %w", err)
+	profile, err := loadProfile(profilePath)
+	if err != nil {
+		return err
 	}
-
-	if err := configureSleepTimeout(); err != nil {
-		return fmt.Errorf("failed to configure sleep timeout: %w", err)
+	if hibernateMinutes > 0 {
+		profile.SetMinutes("hibernate", hibernateMinutes)
+	}
+	if err := profile.Validate(); err != nil {
+		return err
 	}
 
+	// Activate the SleepRight scheme first, all following settings are applied to it.
+	// Without it the remaining steps would change the wrong scheme, so it is fatal.
+	var results stepResults
 	if err := configurePowerScheme(); err != nil {
+		results.add("power scheme", sleepRightSchemeName, err)
+		results.printSummary()
 		return fmt.Errorf("failed to configure power scheme: %w", err)
 	}
+	results.add("power scheme", sleepRightSchemeName, nil)
+
+	results = append(results, configureWakeDevices(profile.WakeDevices())...)
 
-	if err := configureWakeTimers(); err != nil {
-		return fmt.Errorf("failed to configure wake timers: %w", err)
+	// Hibernation must be enabled before a hibernate timeout can take effect
+	if mode := profile.Options["hibernation"]; mode != "" {
+		results.add("hibernation", mode, configureHibernation(mode))
 	}
+	checkHibernateTimeout(profile)
 
-	if hibernateMinutes > 0 {
-		if err := configureHibernateTimeout(hibernateMinutes); err != nil {
-			return fmt.Errorf("failed to configure hibernate timeout: %w", err)
-		}
+	if mode := profile.Options["fast-startup"]; mode != "" {
+		results.add("fast-startup", mode, configureFastStartup(mode))
+	}
+
+	// Opt-in only: changing Modern Standby requires a restart
+	if mode := profile.Options["modern-standby"]; mode != "" {
+		results.add("modern-standby", mode, configureModernStandby(systemRegistry, mode))
 	}
 
+	results = append(results, configureProfileSettings(profile)...)
+
+	// After the profile settings, the window overrides the wake-timers policy
+	if window := profile.Options["maintenance-window"]; window != "" {
+		results.add("maintenance-window", window, configureMaintenanceWindow(window, profile.lookup(findSettingAlias("wake-timers"))))
+	}
+
+	results.printSummary()
+	if results.Failed() {
+		return fmt.Errorf("%d of %d configuration steps failed", results.count(stepFailed)+results.count(stepRequiresAdmin), len(results))
+	}
 	fmt.Println("\nConfiguration completed successfully!")
 	return nil
 }
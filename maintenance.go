@@ -0,0 +1,571 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+// maintenanceArmLead is how long before a maintenance window wake timers are enabled.
+// Windows arms wake timers when the computer goes to sleep, so they have to be allowed
+// already when it goes to sleep before the window. "Important wake timers only" would
+// not wake the computer for the SleepRight task, so until the window ends every wake
+// timer may wake it; the lead is kept short for that reason. A computer that goes to
+// sleep earlier is not woken for the window.
+const maintenanceArmLead = 4 * time.Hour
+
+// Scheduled tasks owned by SleepRight for the maintenance window
+const (
+	maintenanceTaskFolder = `\SleepRight\`
+	maintenanceWakeTask   = "Maintenance Wake"  // wakes the computer and keeps it awake during the window
+	maintenanceApplyTask  = "Maintenance Apply" // switches the wake timer policy before and after the window
+)
+
+// clock is the source of the current time; the system clock except in tests
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the real clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// MaintenanceWindow is a weekly time slot in which the computer may wake, e.g.
+// "Wed 02:00-03:00". No days means every day. An end before the start ends on the
+// next day.
+type MaintenanceWindow struct {
+	Days  []time.Weekday
+	Start time.Duration // offset from midnight
+	End   time.Duration
+}
+
+// weekdayNames maps English and German weekday abbreviations to weekdays
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"so": time.Sunday, "mo": time.Monday, "di": time.Tuesday, "mi": time.Wednesday,
+	"do": time.Thursday, "fr": time.Friday, "sa": time.Saturday,
+}
+
+// parseMaintenanceWindow parses "<days> HH:MM-HH:MM". Days are comma separated
+// abbreviations (English or German) or "daily"/"täglich".
+func parseMaintenanceWindow(value string) (MaintenanceWindow, error) {
+	var window MaintenanceWindow
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) != 2 {
+		return window, fmt.Errorf("invalid maintenance window %q (use e.g. \"Wed 02:00-03:00\")", value)
+	}
+
+	if fields[0] != "daily" && fields[0] != "täglich" {
+		for _, name := range strings.Split(fields[0], ",") {
+			day, ok := weekdayNames[strings.TrimSuffix(name, ".")]
+			if !ok && len(name) > 3 {
+				day, ok = weekdayNames[name[:3]]
+			}
+			if !ok {
+				return window, fmt.Errorf("unknown weekday %q in maintenance window", name)
+			}
+			window.Days = append(window.Days, day)
+		}
+	}
+
+	start, end, found := strings.Cut(fields[1], "-")
+	if !found {
+		return window, fmt.Errorf("invalid time range %q in maintenance window (use HH:MM-HH:MM)", fields[1])
+	}
+	var err error
+	if window.Start, err = parseClockTime(start); err != nil {
+		return window, err
+	}
+	if window.End, err = parseClockTime(end); err != nil {
+		return window, err
+	}
+	if window.Start == window.End {
+		return window, fmt.Errorf("maintenance window %q is empty", value)
+	}
+	return window, nil
+}
+
+// parseClockTime parses HH:MM as offset from midnight
+func parseClockTime(value string) (time.Duration, error) {
+	hours, minutes, found := strings.Cut(value, ":")
+	h, errH := strconv.Atoi(hours)
+	m, errM := strconv.Atoi(minutes)
+	if !found || errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid time %q in maintenance window (use HH:MM)", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// String formats the window in the profile syntax
+func (w MaintenanceWindow) String() string {
+	days := "daily"
+	if len(w.Days) > 0 {
+		names := make([]string, len(w.Days))
+		for i, day := range w.Days {
+			names[i] = day.String()[:3]
+		}
+		days = strings.Join(names, ",")
+	}
+	return fmt.Sprintf("%s %s-%s", days, formatClockTime(w.Start), formatClockTime(w.End))
+}
+
+// formatClockTime formats an offset from midnight as HH:MM
+func formatClockTime(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// Length returns the duration of the window
+func (w MaintenanceWindow) Length() time.Duration {
+	if w.End > w.Start {
+		return w.End - w.Start
+	}
+	return w.End + 24*time.Hour - w.Start
+}
+
+// hasDay reports whether the window starts on the given weekday
+func (w MaintenanceWindow) hasDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// MaintenanceState is the state that applies at a given time
+type MaintenanceState struct {
+	InWindow          bool      // the window is open, the computer should stay awake
+	WakeTimersEnabled bool      // wake timers must be allowed (window open or about to open)
+	WindowStart       time.Time // start of the current or next window
+	WindowEnd         time.Time
+	Next              time.Time // next time the state changes
+}
+
+// StateAt determines the state at the given time: wake timers are enabled from
+// maintenanceArmLead before a window until its end and disabled otherwise.
+func (w MaintenanceWindow) StateAt(now time.Time) MaintenanceState {
+	var state MaintenanceState
+	// A window started yesterday may still be open; a week ahead always contains one
+	for offset := -1; offset <= 8; offset++ {
+		// Start and end are clock times, so on DST days the window keeps its times
+		// and is an hour shorter or longer
+		start := clockTimeOn(now, offset, w.Start)
+		if !w.hasDay(start.Weekday()) {
+			continue
+		}
+		end := clockTimeOn(now, offset, w.End)
+		if w.End <= w.Start {
+			end = clockTimeOn(now, offset+1, w.End)
+		}
+		if !end.After(now) {
+			continue
+		}
+		// Like the apply task's trigger, the arm time is a clock time too
+		arm := clockTimeOn(now, offset, w.Start-maintenanceArmLead)
+		state.WindowStart, state.WindowEnd = start, end
+		switch {
+		case now.Before(arm):
+			state.Next = arm
+		case now.Before(start):
+			state.WakeTimersEnabled = true
+			state.Next = start
+		default:
+			state.InWindow, state.WakeTimersEnabled = true, true
+			state.Next = end
+		}
+		return state
+	}
+	return state
+}
+
+// clockTimeOn returns the clock time (offset from midnight, may be negative) on the
+// day the given number of days after t, in the location of t
+func clockTimeOn(t time.Time, days int, offset time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, t.Location())
+}
+
+// maintenanceConfig is the maintenance window stored by configure for the tasks
+type maintenanceConfig struct {
+	Window    string
+	OutsideAC uint32 // wake-timers values outside the window (from the profile)
+	OutsideDC uint32
+}
+
+// maintenanceConfigPath returns the location of the stored maintenance window
+func maintenanceConfigPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "maintenance.json"), nil
+}
+
+// loadMaintenanceConfig reads the stored maintenance window; nil if none is configured
+func loadMaintenanceConfig() (*maintenanceConfig, MaintenanceWindow, error) {
+	var window MaintenanceWindow
+	path, err := maintenanceConfigPath()
+	if err != nil {
+		return nil, window, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, window, nil
+	} else if err != nil {
+		return nil, window, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var config maintenanceConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, window, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	window, err = parseMaintenanceWindow(config.Window)
+	return &config, window, err
+}
+
+// taskDaysXML is the DaysOfWeek element of a weekly task trigger
+type taskDaysXML struct {
+	Sunday    *struct{} `xml:"Sunday,omitempty"`
+	Monday    *struct{} `xml:"Monday,omitempty"`
+	Tuesday   *struct{} `xml:"Tuesday,omitempty"`
+	Wednesday *struct{} `xml:"Wednesday,omitempty"`
+	Thursday  *struct{} `xml:"Thursday,omitempty"`
+	Friday    *struct{} `xml:"Friday,omitempty"`
+	Saturday  *struct{} `xml:"Saturday,omitempty"`
+}
+
+// calendarTriggerXML is a daily or weekly task trigger
+type calendarTriggerXML struct {
+	StartBoundary string `xml:"StartBoundary"`
+	ScheduleByDay *struct {
+		DaysInterval int `xml:"DaysInterval"`
+	} `xml:"ScheduleByDay,omitempty"`
+	ScheduleByWeek *struct {
+		DaysOfWeek    taskDaysXML `xml:"DaysOfWeek"`
+		WeeksInterval int         `xml:"WeeksInterval"`
+	} `xml:"ScheduleByWeek,omitempty"`
+}
+
+// taskDefinitionXML is the subset of the Task Scheduler schema SleepRight writes
+type taskDefinitionXML struct {
+	XMLName     xml.Name             `xml:"Task"`
+	Version     string               `xml:"version,attr"`
+	Namespace   string               `xml:"xmlns,attr"`
+	Description string               `xml:"RegistrationInfo>Description"`
+	Triggers    []calendarTriggerXML `xml:"Triggers>CalendarTrigger"`
+	UserID      string               `xml:"Principals>Principal>UserId"`
+	RunLevel    string               `xml:"Principals>Principal>RunLevel"`
+	Settings    struct {
+		MultipleInstancesPolicy    string `xml:"MultipleInstancesPolicy"`
+		DisallowStartIfOnBatteries bool   `xml:"DisallowStartIfOnBatteries"`
+		StopIfGoingOnBatteries     bool   `xml:"StopIfGoingOnBatteries"`
+		StartWhenAvailable         bool   `xml:"StartWhenAvailable"`
+		WakeToRun                  bool   `xml:"WakeToRun"`
+		ExecutionTimeLimit         string `xml:"ExecutionTimeLimit"`
+	} `xml:"Settings"`
+	Command   string `xml:"Actions>Exec>Command"`
+	Arguments string `xml:"Actions>Exec>Arguments"`
+}
+
+// maintenanceTrigger returns a trigger at the given offset from midnight on the
+// window days; the offset may be negative or exceed a day
+func maintenanceTrigger(w MaintenanceWindow, offset time.Duration) calendarTriggerXML {
+	// Any past date works as start boundary; 2024-01-07 is a Sunday
+	base := time.Date(2024, 1, 7, 0, 0, 0, 0, time.Local)
+	shiftDays := 0
+	for offset < 0 {
+		offset += 24 * time.Hour
+		shiftDays--
+	}
+	for offset >= 24*time.Hour {
+		offset -= 24 * time.Hour
+		shiftDays++
+	}
+	trigger := calendarTriggerXML{StartBoundary: base.AddDate(0, 0, 7).Add(offset).Format("2006-01-02T15:04:05")}
+	if len(w.Days) == 0 {
+		trigger.ScheduleByDay = &struct {
+			DaysInterval int `xml:"DaysInterval"`
+		}{DaysInterval: 1}
+		return trigger
+	}
+
+	trigger.ScheduleByWeek = &struct {
+		DaysOfWeek    taskDaysXML `xml:"DaysOfWeek"`
+		WeeksInterval int         `xml:"WeeksInterval"`
+	}{WeeksInterval: 1}
+	days := &trigger.ScheduleByWeek.DaysOfWeek
+	for _, day := range w.Days {
+		set := &struct{}{}
+		switch time.Weekday((int(day) + shiftDays + 7) % 7) {
+		case time.Sunday:
+			days.Sunday = set
+		case time.Monday:
+			days.Monday = set
+		case time.Tuesday:
+			days.Tuesday = set
+		case time.Wednesday:
+			days.Wednesday = set
+		case time.Thursday:
+			days.Thursday = set
+		case time.Friday:
+			days.Friday = set
+		case time.Saturday:
+			days.Saturday = set
+		}
+	}
+	return trigger
+}
+
+// maintenanceTaskXML builds the definition of a SleepRight maintenance task running
+// as SYSTEM. The wake task wakes the computer at the window start, the apply task
+// runs before the window and at its end (and later if the computer was off).
+func maintenanceTaskXML(w MaintenanceWindow, exe string, wake bool) ([]byte, error) {
+	task := taskDefinitionXML{
+		Version:   "1.2",
+		Namespace: "http://schemas.microsoft.com/windows/2004/02/mit/task",
+		UserID:    "S-1-5-18",
+		RunLevel:  "HighestAvailable",
+		Command:   exe,
+	}
+	task.Settings.MultipleInstancesPolicy = "IgnoreNew"
+	if wake {
+		task.Description = "Wakes the computer for the maintenance window " + w.String() + " (managed by SleepRight)"
+		task.Triggers = []calendarTriggerXML{maintenanceTrigger(w, w.Start)}
+		task.Settings.WakeToRun = true
+		task.Settings.ExecutionTimeLimit = fmt.Sprintf("PT%dM", int((w.Length()+10*time.Minute)/time.Minute))
+		task.Arguments = "maintenance hold"
+	} else {
+		task.Description = "Switches wake timers for the maintenance window " + w.String() + " (managed by SleepRight)"
+		task.Triggers = []calendarTriggerXML{maintenanceTrigger(w, w.Start-maintenanceArmLead), maintenanceTrigger(w, w.Start+w.Length())}
+		task.Settings.StartWhenAvailable = true
+		task.Settings.ExecutionTimeLimit = "PT10M"
+		task.Arguments = "maintenance apply"
+	}
+
+	data, err := xml.MarshalIndent(task, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("<?xml version=\"1.0\" encoding=\"UTF-16\"?>\n"), data...), nil
+}
+
+// registerMaintenanceTask creates or replaces a SleepRight task. schtasks expects the
+// XML file in UTF-16.
+func registerMaintenanceTask(name string, definition []byte) error {
+	encoded, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes(definition)
+	if err != nil {
+		return fmt.Errorf("failed to encode task %s: %w", name, err)
+	}
+	file, err := os.CreateTemp("", "sleepright-task-*.xml")
+	if err != nil {
+		return fmt.Errorf("failed to create task file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(encoded)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+
+	change := auditChange{Action: "task", Target: maintenanceTaskFolder + name, New: "registered"}
+	if err := runAudited(change, "schtasks", "/create", "/tn", maintenanceTaskFolder+name, "/xml", file.Name(), "/f"); err != nil {
+		return fmt.Errorf("failed to register task %s: %w", name, err)
+	}
+	return nil
+}
+
+// configureMaintenanceWindow stores the window, registers the SleepRight tasks and
+// applies the wake timer policy for the current time. outside is the wake-timers
+// profile setting used outside the window (nil: disabled).
+func configureMaintenanceWindow(value string, outside *ProfileSetting) error {
+	fmt.Printf("Configuring maintenance window (%s)...\n", value)
+
+	window, err := parseMaintenanceWindow(value)
+	if err != nil {
+		return err
+	}
+	config := maintenanceConfig{Window: window.String()}
+	if outside != nil {
+		if outside.HasAC {
+			config.OutsideAC = outside.AC
+		}
+		if outside.HasDC {
+			config.OutsideDC = outside.DC
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to determine executable path: %w", err)
+	}
+	for _, wake := range []bool{true, false} {
+		name := maintenanceApplyTask
+		if wake {
+			name = maintenanceWakeTask
+		}
+		definition, err := maintenanceTaskXML(window, exe, wake)
+		if err != nil {
+			return fmt.Errorf("failed to build task %s: %w", name, err)
+		}
+		if err := registerMaintenanceTask(name, definition); err != nil {
+			return err
+		}
+		fmt.Printf("  Task %s%s registered.\n", maintenanceTaskFolder, name)
+	}
+
+	path, err := maintenanceConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode maintenance window: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return applyMaintenanceState(&config, window, systemClock{})
+}
+
+// applyMaintenanceState sets the wake timer policy for the current state of the window
+func applyMaintenanceState(config *maintenanceConfig, window MaintenanceWindow, c clock) error {
+	alias := findSettingAlias("wake-timers")
+	state := window.StateAt(c.Now())
+	ac, dc := config.OutsideAC, config.OutsideDC
+	if state.WakeTimersEnabled {
+		ac, dc = 1, 1
+	}
+	if err := writeSettingIndex("ac", alias.Subgroup, alias.Setting, ac); err != nil {
+		return err
+	}
+	if err := writeSettingIndex("dc", alias.Subgroup, alias.Setting, dc); err != nil {
+		return err
+	}
+	if err := applyActiveScheme(); err != nil {
+		return err
+	}
+	fmt.Printf("  Wake timers set to %s (AC) / %s (DC) until %s.\n",
+		alias.FormatValue(ac), alias.FormatValue(dc), state.Next.Format("Mon 2006-01-02 15:04"))
+	return nil
+}
+
+// holdMaintenanceWindow keeps the computer awake until the window ends and then
+// applies the wake timer policy for the time after the window
+func holdMaintenanceWindow(config *maintenanceConfig, window MaintenanceWindow, c clock) error {
+	state := window.StateAt(c.Now())
+	if state.InWindow {
+		fmt.Printf("Maintenance window open until %s, keeping the computer awake.\n", state.WindowEnd.Format("15:04"))
+		keepSystemAwake(true)
+		c.Sleep(state.WindowEnd.Sub(c.Now()))
+		keepSystemAwake(false)
+	}
+	return applyMaintenanceState(config, window, c)
+}
+
+// removeMaintenanceWindow deletes the SleepRight tasks and the stored window
+func removeMaintenanceWindow() error {
+	for _, name := range []string{maintenanceWakeTask, maintenanceApplyTask} {
+		change := auditChange{Action: "task", Target: maintenanceTaskFolder + name, New: "deleted"}
+		if err := runAudited(change, "schtasks", "/delete", "/tn", maintenanceTaskFolder+name, "/f"); err != nil {
+			fmt.Printf("  Task %s%s not found.\n", maintenanceTaskFolder, name)
+		} else {
+			fmt.Printf("  Task %s%s removed.\n", maintenanceTaskFolder, name)
+		}
+	}
+	path, err := maintenanceConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// showMaintenanceWindow prints the configured window and the current state
+func showMaintenanceWindow(config *maintenanceConfig, window MaintenanceWindow, c clock) {
+	printUTF8ln("\nWartungsfenster:")
+	if config == nil {
+		printUTF8ln("  Nicht konfiguriert")
+		return
+	}
+	state := window.StateAt(c.Now())
+	printUTF8ln("  Zeitfenster: %s", window.String())
+	printUTF8ln("  Nächstes Fenster: %s bis %s", state.WindowStart.Format("02.01.2006 15:04"), state.WindowEnd.Format("15:04"))
+	switch {
+	case state.InWindow:
+		printUTF8ln("  Zustand: Fenster geöffnet, Zeitgeber zur Aktivierung zugelassen")
+	case state.WakeTimersEnabled:
+		printUTF8ln("  Zustand: Zeitgeber zur Aktivierung für das nächste Fenster zugelassen")
+	default:
+		printUTF8ln("  Zustand: Zeitgeber zur Aktivierung gesperrt")
+	}
+	printUTF8ln("  Nächste Änderung: %s", state.Next.Format("02.01.2006 15:04"))
+}
+
+// runMaintenanceCommand shows the maintenance window or runs the steps of the
+// SleepRight tasks (apply, hold) and removes the window (remove)
+func runMaintenanceCommand(args []string) int {
+	if len(args) == 1 && args[0] == "remove" {
+		if err := removeMaintenanceWindow(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	config, window, err := loadMaintenanceConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(args) == 0 {
+		showMaintenanceWindow(config, window, systemClock{})
+		return 0
+	}
+	if config == nil {
+		fmt.Fprintf(os.Stderr, "Error: no maintenance window configured (add \"maintenance-window\" to the profile)\n")
+		return 1
+	}
+
+	switch args[0] {
+	case "apply":
+		err = applyMaintenanceState(config, window, systemClock{})
+	case "hold":
+		err = holdMaintenanceWindow(config, window, systemClock{})
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight maintenance [apply|hold|remove]\n")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// maintenanceWindowOption validates the maintenance-window profile value
+func maintenanceWindowOption(value string) (string, error) {
+	window, err := parseMaintenanceWindow(value)
+	if err != nil {
+		return "", err
+	}
+	return window.String(), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestMaintenanceWindowStateAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, berlin)
+	}
+
+	// 14.10.2026 is a Wednesday; the clocks go forward on 29.03.2026 and back on
+	// 25.10.2026 (both Sundays)
+	tests := []struct {
+		name       string
+		window     string
+		now        time.Time
+		inWindow   bool
+		wakeTimers bool
+		start, end time.Time
+		next       time.Time
+	}{
+		{"days before", "Wed 02:00-03:00", at(10, 12, 10, 0), false, false,
+			at(10, 14, 2, 0), at(10, 14, 3, 0), at(10, 13, 22, 0)},
+		{"just before arming", "Wed 02:00-03:00", at(10, 13, 21, 59), false, false,
+			at(10, 14, 2, 0), at(10, 14, 3, 0), at(10, 13, 22, 0)},
+		{"armed 4h before", "Wed 02:00-03:00", at(10, 13, 22, 0), false, true,
+			at(10, 14, 2, 0), at(10, 14, 3, 0), at(10, 14, 2, 0)},
+		{"window starts", "Wed 02:00-03:00", at(10, 14, 2, 0), true, true,
+			at(10, 14, 2, 0), at(10, 14, 3, 0), at(10, 14, 3, 0)},
+		{"inside", "Wed 02:00-03:00", at(10, 14, 2, 30), true, true,
+			at(10, 14, 2, 0), at(10, 14, 3, 0), at(10, 14, 3, 0)},
+		{"window ended", "Wed 02:00-03:00", at(10, 14, 3, 0), false, false,
+			at(10, 21, 2, 0), at(10, 21, 3, 0), at(10, 20, 22, 0)},
+		{"weekly, several days", "Mon,Thu 02:00-03:00", at(10, 13, 10, 0), false, false,
+			at(10, 15, 2, 0), at(10, 15, 3, 0), at(10, 14, 22, 0)},
+		{"daily, after the window", "daily 02:00-03:00", at(10, 14, 3, 30), false, false,
+			at(10, 15, 2, 0), at(10, 15, 3, 0), at(10, 14, 22, 0)},
+		{"daily, armed", "täglich 02:00-03:00", at(10, 14, 23, 0), false, true,
+			at(10, 15, 2, 0), at(10, 15, 3, 0), at(10, 15, 2, 0)},
+		{"across midnight, before midnight", "Sat 23:00-01:00", at(10, 17, 23, 30), true, true,
+			at(10, 17, 23, 0), at(10, 18, 1, 0), at(10, 18, 1, 0)},
+		{"across midnight, after midnight", "Sat 23:00-01:00", at(10, 18, 0, 30), true, true,
+			at(10, 17, 23, 0), at(10, 18, 1, 0), at(10, 18, 1, 0)},
+		{"across midnight, ended", "Sat 23:00-01:00", at(10, 18, 1, 0), false, false,
+			at(10, 24, 23, 0), at(10, 25, 1, 0), at(10, 24, 19, 0)},
+		{"spring forward, before the window", "Sun 04:00-05:00", at(3, 29, 3, 30), false, true,
+			at(3, 29, 4, 0), at(3, 29, 5, 0), at(3, 29, 4, 0)},
+		{"spring forward, armed at the clock time", "Sun 04:00-05:00", at(3, 29, 0, 30), false, true,
+			at(3, 29, 4, 0), at(3, 29, 5, 0), at(3, 29, 4, 0)},
+		{"fall back, before the window", "Sun 04:00-05:00", at(10, 25, 3, 30), false, true,
+			at(10, 25, 4, 0), at(10, 25, 5, 0), at(10, 25, 4, 0)},
+		{"fall back, armed at the clock time", "Sun 04:00-05:00", at(10, 25, 0, 30), false, true,
+			at(10, 25, 4, 0), at(10, 25, 5, 0), at(10, 25, 4, 0)},
+		{"fall back, window spans the change", "Sun 01:00-04:00", at(10, 25, 3, 30), true, true,
+			at(10, 25, 1, 0), at(10, 25, 4, 0), at(10, 25, 4, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := parseMaintenanceWindow(tt.window)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := w.StateAt(tt.now)
+			if state.InWindow != tt.inWindow || state.WakeTimersEnabled != tt.wakeTimers {
+				t.Errorf("InWindow = %t, WakeTimersEnabled = %t, want %t, %t",
+					state.InWindow, state.WakeTimersEnabled, tt.inWindow, tt.wakeTimers)
+			}
+			if !state.WindowStart.Equal(tt.start) || !state.WindowEnd.Equal(tt.end) {
+				t.Errorf("window = %s - %s, want %s - %s", state.WindowStart, state.WindowEnd, tt.start, tt.end)
+			}
+			if !state.Next.Equal(tt.next) {
+				t.Errorf("Next = %s, want %s", state.Next, tt.next)
+			}
+		})
+	}
+}
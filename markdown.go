@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// showInfoMarkdown prints the -info/-info-full output as Markdown
+func showInfoMarkdown(full bool) error {
+	glyphs := unicodeTimelineGlyphs
+	if !consoleIsUTF8() {
+		glyphs = asciiTimelineGlyphs
+	}
+	report := collectReport(full, glyphs)
+
+	var b strings.Builder
+	if err := renderMarkdownReport(&b, report, full); err != nil {
+		return fmt.Errorf("Fehler beim Erzeugen der Markdown-Ausgabe: %w", err)
+	}
+	printUTF8("%s", b.String())
+	return nil
+}
+
+// renderMarkdownReport writes the report as Markdown (headings and tables) for issue
+// trackers and wikis. In summary mode only wake-armed devices are listed.
+func renderMarkdownReport(w io.Writer, report *Report, full bool) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# SleepRight-Bericht: %s\n\n", mdEscape(report.Hostname))
+	fmt.Fprintf(&b, "_Erstellt: %s · SleepRight v%s_\n\n", report.Generated.Local().Format("02.01.2006 15:04:05"), report.Version)
+
+	if len(report.Errors) > 0 {
+		b.WriteString("> **Folgende Daten konnten nicht ermittelt werden:**\n")
+		for _, e := range report.Errors {
+			fmt.Fprintf(&b, "> - %s\n", e)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Energieschema\n\n")
+	if report.SchemeName != "" {
+		fmt.Fprintf(&b, "**%s** (`%s`)\n\n", mdEscape(report.SchemeName), report.SchemeGUID)
+	} else {
+		b.WriteString("Unbekannt\n\n")
+	}
+
+	b.WriteString("## Zeitlimits\n\n")
+	b.WriteString("| Einstellung | Netzbetrieb (AC) | Batterie (DC) |\n")
+	b.WriteString("|---|---|---|\n")
+	for _, timeout := range report.Timeouts {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", mdEscape(timeout.Name),
+			mdTimeout(timeout.AC, timeout.HasAC), mdTimeout(timeout.DC, timeout.HasDC))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Aufweck-Geräte\n\n")
+	fmt.Fprintf(&b, "Aktuell aufweck-aktivierte Geräte: %d von %d aufweck-programmierbaren Geräten\n\n", report.ArmedCount, len(report.WakeDevices))
+	b.WriteString("| Gerät | Aufwecken | Magic-Packet |\n")
+	b.WriteString("|---|---|---|\n")
+	for _, device := range report.WakeDevices {
+		if !full && !device.Armed {
+			continue
+		}
+		armed := "Deaktiviert"
+		if device.Armed {
+			armed = "**Aktiviert**"
+		}
+		magicPacket := "–"
+		if device.IsNetwork {
+			magicPacket = "Deaktiviert"
+			if device.MagicPacketOnly {
+				magicPacket = "Aktiviert"
+			}
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", mdEscape(device.Name), armed, magicPacket)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Auffälligkeiten\n\n")
+	if len(report.Findings) == 0 {
+		b.WriteString("Keine Auffälligkeiten gefunden.\n")
+	}
+	for _, finding := range report.Findings {
+		fmt.Fprintf(&b, "- **%s**  \n  Vermutete Ursache: %s", mdEscape(finding.Title), mdEscape(finding.Cause))
+		if len(finding.Evidence) > 0 {
+			var stamps []string
+			for _, t := range finding.Evidence {
+				stamps = append(stamps, t.Local().Format("02.01.2006 15:04:05"))
+			}
+			fmt.Fprintf(&b, "  \n  Belege: %s", strings.Join(stamps, ", "))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Aufweck-Ereignisse\n\n")
+	if len(report.Events) == 0 {
+		b.WriteString("Keine Power-Troubleshooter-Ereignisse gefunden.\n\n")
+	} else {
+		b.WriteString("| Aufwachzeit | Schlafbeginn | Schlafdauer | Quelle |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, event := range report.Events {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				event.Timestamp.Local().Format("02.01.2006 15:04:05"),
+				event.SleepTime.Local().Format("02.01.2006 15:04:05"),
+				formatDuration(event.Timestamp.Sub(event.SleepTime)),
+				mdEscape(event.Source))
+		}
+		b.WriteString("\n")
+	}
+
+	if full {
+		b.WriteString("## Schlaf-Zeitleiste\n\n")
+		mdCodeBlock(&b, strings.Join(report.Timeline, "\n"))
+	}
+
+	b.WriteString("## Aufweck-Zeitgeber\n\n")
+	mdCodeBlock(&b, report.WakeTimers)
+
+	b.WriteString("## Energieanfragen\n\n")
+	mdCodeBlock(&b, report.PowerRequests)
+
+	if full {
+		b.WriteString("## Verfügbare Standby-Zustände\n\n")
+		mdCodeBlock(&b, report.SleepStates)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdEscape escapes characters that would break Markdown tables or formatting
+func mdEscape(s string) string {
+	replacer := strings.NewReplacer("|", "\\|", "\r", "", "\n", " ", "*", "\\*", "_", "\\_")
+	return replacer.Replace(strings.TrimSpace(s))
+}
+
+// mdTimeout formats a timeout table cell
+func mdTimeout(seconds int, found bool) string {
+	if !found {
+		return "nicht verfügbar"
+	}
+	return formatTimeout(seconds)
+}
+
+// mdCodeBlock writes text as a fenced code block
+func mdCodeBlock(b *strings.Builder, text string) {
+	b.WriteString("```\n")
+	b.WriteString(strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n"))
+	b.WriteString("\n```\n\n")
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Registry values controlling Modern Standby (S0 Low Power Idle) below powerControlKey.
+// PlatformAoAcOverride = 0 disables Modern Standby on Windows 10 2004 and later,
+// CsEnabled = 0 on older builds.
+const (
+	aoAcOverrideValue = "PlatformAoAcOverride"
+	csEnabledValue    = "CsEnabled"
+)
+
+// networkStandbySetting is "Networking connectivity in Standby" (catalog name
+// standby-network) in the subgroup of settings without subgroup
+const networkStandbySetting = "f15576e8-98b7-4186-b944-eafa664402d9"
+
+// ModernStandbyState describes Modern Standby and the S3 support of the firmware
+type ModernStandbyState struct {
+	S0Available     bool // "Standby (S0 Low Power Idle)" is available
+	S3Available     bool
+	S3Firmware      bool // the firmware supports S3 (it may be disabled because of S0)
+	AoAcOverride    uint32
+	HasAoAcOverride bool
+	CsEnabled       uint32
+	HasCsEnabled    bool
+}
+
+// modernStandbyFromSleepStates derives the S0/S3 part of the state from powercfg /a.
+// S3 counts as supported by the firmware unless one of its reasons mentions the
+// firmware ("The system firmware does not support this standby state").
+func modernStandbyFromSleepStates(states SleepStates) ModernStandbyState {
+	var state ModernStandbyState
+	for _, name := range states.Available {
+		if strings.Contains(name, "S0") {
+			state.S0Available = true
+		}
+		if strings.Contains(name, "(S3)") {
+			state.S3Available, state.S3Firmware = true, true
+		}
+	}
+	for _, unavailable := range states.Unavailable {
+		if !strings.Contains(unavailable.Name, "(S3)") {
+			continue
+		}
+		state.S3Firmware = true
+		for _, reason := range unavailable.Reasons {
+			if contains(reason, "firmware") {
+				state.S3Firmware = false
+			}
+		}
+	}
+	return state
+}
+
+// queryModernStandby collects the Modern Standby state from powercfg /a and the registry
+func queryModernStandby(reg registryStore) (ModernStandbyState, error) {
+	output, err := runCommandWithEncoding("powercfg", "/a")
+	if err != nil {
+		return ModernStandbyState{}, fmt.Errorf("Fehler beim Ausführen von powercfg /a: %w", err)
+	}
+	state := modernStandbyFromSleepStates(parseSleepStates(output))
+	if state.AoAcOverride, state.HasAoAcOverride, err = reg.GetDWORD(powerControlKey, aoAcOverrideValue); err != nil {
+		return state, err
+	}
+	if state.CsEnabled, state.HasCsEnabled, err = reg.GetDWORD(powerControlKey, csEnabledValue); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// showModernStandby prints the Modern Standby state and the possible remedies
+func showModernStandby() error {
+	state, err := queryModernStandby(systemRegistry)
+	if err != nil {
+		return err
+	}
+
+	printUTF8ln("\nModern Standby (S0 Low Power Idle):")
+	if state.S0Available {
+		printUTF8ln("  Aktiv: Ja")
+	} else {
+		printUTF8ln("  Aktiv: Nein")
+	}
+	printUTF8ln("  %s: %s", aoAcOverrideValue, formatRegistryDWORD(state.AoAcOverride, state.HasAoAcOverride))
+	printUTF8ln("  %s: %s", csEnabledValue, formatRegistryDWORD(state.CsEnabled, state.HasCsEnabled))
+	switch {
+	case state.S3Available:
+		printUTF8ln("  Klassischer Standby (S3): verfügbar")
+	case state.S3Firmware:
+		printUTF8ln("  Klassischer Standby (S3): von der Firmware unterstützt, aber wegen Modern Standby deaktiviert")
+	default:
+		printUTF8ln("  Klassischer Standby (S3): von der Firmware nicht unterstützt")
+	}
+
+	if state.S0Available {
+		printUTF8ln("  Abhilfe (im Profil):")
+		printUTF8ln("    modern-standby: no-network   Netzwerkverbindung im Standby deaktivieren")
+		if state.S3Firmware {
+			printUTF8ln("    modern-standby: off          Modern Standby abschalten, S3 verwenden (Neustart erforderlich)")
+		}
+	}
+	return nil
+}
+
+// detectStandbyNetwork reports a finding if Modern Standby is active and the network
+// stays connected in standby (enabled or managed by Windows) on AC or DC
+func detectStandbyNetwork(state ModernStandbyState, setting *PowerSetting) []Finding {
+	if !state.S0Available || setting == nil {
+		return nil
+	}
+	if (!setting.HasAC || setting.AC == 0) && (!setting.HasDC || setting.DC == 0) {
+		return nil
+	}
+	alias := findSettingAlias("standby-network")
+	return []Finding{{
+		Kind: findingStandbyNetwork,
+		Title: fmt.Sprintf("Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: %s, Batterie: %s)",
+			formatAliasIndex(alias, setting.AC, setting.HasAC), formatAliasIndex(alias, setting.DC, setting.HasDC)),
+		Cause: "Im Modern Standby (S0) hält die Netzwerkkarte das System teilweise wach, Updates und Synchronisation können es aufwecken. " +
+			"Abhilfe: SleepRight set standby-network disabled",
+	}}
+}
+
+// querySystemFindings returns findings derived from the current configuration rather
+// than from wake events. Errors only suppress the affected findings.
+func querySystemFindings() []Finding {
+	state, err := queryModernStandby(systemRegistry)
+	if err != nil || !state.S0Available {
+		return nil
+	}
+	scheme, err := queryPowerScheme("", true)
+	if err != nil {
+		return nil
+	}
+	return systemFindings(state, scheme)
+}
+
+// systemFindings derives the findings from the Modern Standby state and the active
+// scheme (queried with hidden settings)
+func systemFindings(state ModernStandbyState, scheme *PowerScheme) []Finding {
+	_, setting := scheme.Find(subgroupNone, networkStandbySetting)
+	return detectStandbyNetwork(state, setting)
+}
+
+// formatRegistryDWORD formats a registry value that may be missing
+func formatRegistryDWORD(value uint32, found bool) string {
+	if !found {
+		return "nicht gesetzt"
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// modernStandbySnapshot records the state before SleepRight changed Modern Standby so
+// "SleepRight modern-standby undo" can restore it. Nil values did not exist.
+type modernStandbySnapshot struct {
+	Created      time.Time
+	AoAcOverride *uint32
+	CsEnabled    *uint32
+	NetworkAC    *uint32
+	NetworkDC    *uint32
+}
+
+// modernStandbySnapshotPath returns the location of the undo snapshot
+func modernStandbySnapshotPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "modern-standby-undo.json"), nil
+}
+
+// saveModernStandbySnapshot stores the current state unless a snapshot exists already,
+// so repeated configure runs keep the original state
+func saveModernStandbySnapshot(reg registryStore) error {
+	path, err := modernStandbySnapshotPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	snapshot := modernStandbySnapshot{Created: time.Now()}
+	if value, found, err := reg.GetDWORD(powerControlKey, aoAcOverrideValue); err != nil {
+		return err
+	} else if found {
+		snapshot.AoAcOverride = &value
+	}
+	if value, found, err := reg.GetDWORD(powerControlKey, csEnabledValue); err != nil {
+		return err
+	} else if found {
+		snapshot.CsEnabled = &value
+	}
+	if scheme, err := queryPowerScheme("", true); err == nil {
+		if _, setting := scheme.Find(subgroupNone, networkStandbySetting); setting != nil {
+			if setting.HasAC {
+				snapshot.NetworkAC = &setting.AC
+			}
+			if setting.HasDC {
+				snapshot.NetworkDC = &setting.DC
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", path, err)
+	}
+	fmt.Printf("  Previous state saved to %s\n", path)
+	return nil
+}
+
+// configureModernStandby applies the modern-standby profile key: "no-network" keeps
+// Modern Standby but disconnects the network in standby, "off" disables Modern Standby
+// (only if the firmware supports S3, otherwise the system could not sleep at all)
+func configureModernStandby(reg registryStore, mode string) error {
+	fmt.Printf("Configuring Modern Standby (%s)...\n", mode)
+
+	state, err := queryModernStandby(reg)
+	if err != nil {
+		return err
+	}
+	return applyModernStandby(reg, state, mode)
+}
+
+// applyModernStandby changes the registry or power settings for the given state.
+// Without S0 there is nothing to disable, so "off" leaves the registry alone.
+func applyModernStandby(reg registryStore, state ModernStandbyState, mode string) error {
+	if mode == "off" && !state.S0Available {
+		if state.HasAoAcOverride && state.AoAcOverride == 0 {
+			fmt.Println("  Modern Standby is already disabled.")
+			return skipped("already disabled")
+		}
+		fmt.Println("  Modern Standby is not used on this computer.")
+		return skipped("Modern Standby not active")
+	}
+	if mode == "off" && !state.S3Firmware {
+		return fmt.Errorf("the firmware does not support S3 standby; disabling Modern Standby would leave no standby state")
+	}
+
+	if err := saveModernStandbySnapshot(reg); err != nil {
+		return err
+	}
+
+	switch mode {
+	case "no-network":
+		if err := writeSettingIndex("ac", subgroupNone, networkStandbySetting, 0); err != nil {
+			return err
+		}
+		if err := writeSettingIndex("dc", subgroupNone, networkStandbySetting, 0); err != nil {
+			return err
+		}
+		if err := applyActiveScheme(); err != nil {
+			return err
+		}
+		fmt.Println("  Network connectivity in standby disabled.")
+	case "off":
+		if err := disableModernStandby(reg, state); err != nil {
+			return err
+		}
+		fmt.Println("  A restart is required for the change to take effect.")
+	default:
+		return fmt.Errorf("unknown Modern Standby mode %q (use off or no-network)", mode)
+	}
+	return nil
+}
+
+// disableModernStandby sets PlatformAoAcOverride to 0 and, on builds that still use
+// it, CsEnabled as well
+func disableModernStandby(reg registryStore, state ModernStandbyState) error {
+	if err := reg.SetDWORD(powerControlKey, aoAcOverrideValue, 0); err != nil {
+		return err
+	}
+	fmt.Printf("  %s set to 0, Modern Standby disabled.\n", aoAcOverrideValue)
+	if state.HasCsEnabled && state.CsEnabled != 0 {
+		if err := reg.SetDWORD(powerControlKey, csEnabledValue, 0); err != nil {
+			return err
+		}
+		fmt.Printf("  %s set to 0.\n", csEnabledValue)
+	}
+	return nil
+}
+
+// undoModernStandby restores the state saved before SleepRight changed Modern Standby
+func undoModernStandby(reg registryStore) error {
+	path, err := modernStandbySnapshotPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no Modern Standby snapshot found (%s)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snapshot modernStandbySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+
+	for name, value := range map[string]*uint32{aoAcOverrideValue: snapshot.AoAcOverride, csEnabledValue: snapshot.CsEnabled} {
+		if value == nil {
+			err = reg.DeleteValue(powerControlKey, name)
+		} else {
+			err = reg.SetDWORD(powerControlKey, name, *value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if snapshot.NetworkAC != nil {
+		if err := writeSettingIndex("ac", subgroupNone, networkStandbySetting, *snapshot.NetworkAC); err != nil {
+			return err
+		}
+	}
+	if snapshot.NetworkDC != nil {
+		if err := writeSettingIndex("dc", subgroupNone, networkStandbySetting, *snapshot.NetworkDC); err != nil {
+			return err
+		}
+	}
+	if err := applyActiveScheme(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove snapshot %s: %w", path, err)
+	}
+	fmt.Printf("Modern Standby settings restored from %s (saved %s).\n", path, snapshot.Created.Local().Format("2006-01-02 15:04"))
+	fmt.Println("A restart is required for the change to take effect.")
+	return nil
+}
+
+// runModernStandbyCommand shows the Modern Standby state or undoes SleepRight's changes
+func runModernStandbyCommand(args []string) int {
+	switch {
+	case len(args) == 0:
+		if err := showModernStandby(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	case args[0] == "undo":
+		if err := undoModernStandby(systemRegistry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight modern-standby [undo]\n")
+		return 1
+	}
+	return 0
+}
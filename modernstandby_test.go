@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDisableModernStandby(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  ModernStandbyState
+		values map[string]uint32
+		want   map[string]uint32
+	}{
+		{
+			name:  "current build without CsEnabled",
+			state: ModernStandbyState{S0Available: true},
+			want:  map[string]uint32{aoAcOverrideValue: 0},
+		},
+		{
+			name:   "older build with CsEnabled",
+			state:  ModernStandbyState{S0Available: true, CsEnabled: 1, HasCsEnabled: true},
+			values: map[string]uint32{csEnabledValue: 1},
+			want:   map[string]uint32{aoAcOverrideValue: 0, csEnabledValue: 0},
+		},
+		{
+			name:   "CsEnabled already 0",
+			state:  ModernStandbyState{S0Available: true, AoAcOverride: 1, HasAoAcOverride: true, HasCsEnabled: true},
+			values: map[string]uint32{aoAcOverrideValue: 1, csEnabledValue: 0},
+			want:   map[string]uint32{aoAcOverrideValue: 0, csEnabledValue: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newMemoryRegistry()
+			for name, value := range tt.values {
+				reg.values[powerControlKey+`\`+name] = value
+			}
+			if err := disableModernStandby(reg, tt.state); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{aoAcOverrideValue, csEnabledValue} {
+				got, found := reg.values[powerControlKey+`\`+name]
+				want, wantFound := tt.want[name]
+				if got != want || found != wantFound {
+					t.Errorf("%s = %d (found %t), want %d (found %t)", name, got, found, want, wantFound)
+				}
+			}
+		})
+	}
+
+	reg := newMemoryRegistry()
+	reg.setErr = errAccessDenied
+	if err := disableModernStandby(reg, ModernStandbyState{S0Available: true}); err == nil {
+		t.Error("write error not returned")
+	}
+}
+
+func TestApplyModernStandbyOff(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      ModernStandbyState
+		values     map[string]uint32
+		wantStatus stepStatus
+		wantWrites int
+	}{
+		{"no S0", ModernStandbyState{S3Available: true, S3Firmware: true}, nil, stepSkipped, 0},
+		{"no S0 and no S3", ModernStandbyState{}, nil, stepSkipped, 0},
+		{"already disabled", ModernStandbyState{S3Available: true, S3Firmware: true, HasAoAcOverride: true},
+			map[string]uint32{aoAcOverrideValue: 0}, stepSkipped, 0},
+		{"S0 without S3 firmware", ModernStandbyState{S0Available: true}, nil, stepFailed, 0},
+		{"S0 with S3 firmware", ModernStandbyState{S0Available: true, S3Firmware: true}, nil, stepApplied, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ProgramData", t.TempDir())
+			reg := newMemoryRegistry()
+			for name, value := range tt.values {
+				reg.values[powerControlKey+`\`+name] = value
+			}
+			err := applyModernStandby(reg, tt.state, "off")
+			if status := classifyStepError(err); status != tt.wantStatus {
+				t.Errorf("status = %s (%v), want %s", status, err, tt.wantStatus)
+			}
+			if reg.writes != tt.wantWrites {
+				t.Errorf("%d registry writes, want %d", reg.writes, tt.wantWrites)
+			}
+			path, err := modernStandbySnapshotPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(path); (err == nil) != (tt.wantWrites > 0) {
+				t.Errorf("undo snapshot written: %t, want %t", err == nil, tt.wantWrites > 0)
+			}
+		})
+	}
+}
+
+func TestDetectStandbyNetwork(t *testing.T) {
+	s0 := ModernStandbyState{S0Available: true}
+	network := func(ac, dc uint32) *PowerSetting {
+		return &PowerSetting{GUID: networkStandbySetting, AC: ac, DC: dc, HasAC: true, HasDC: true}
+	}
+	acOnly := &PowerSetting{GUID: networkStandbySetting, AC: 2, HasAC: true}
+
+	tests := []struct {
+		name    string
+		state   ModernStandbyState
+		setting *PowerSetting
+		want    string // title, empty for no finding
+	}{
+		{"disconnected", s0, network(0, 0), ""},
+		{"connected", s0, network(1, 1), "Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: enabled, Batterie: enabled)"},
+		{"managed by Windows", s0, network(2, 2), "Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: managed, Batterie: managed)"},
+		{"connected on AC only", s0, network(1, 0), "Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: enabled, Batterie: disabled)"},
+		{"managed on battery only", s0, network(0, 2), "Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: disabled, Batterie: managed)"},
+		{"no DC value", s0, acOnly, "Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: managed, Batterie: -)"},
+		{"no Modern Standby", ModernStandbyState{S3Available: true, S3Firmware: true}, network(1, 1), ""},
+		{"setting not in the scheme", s0, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := detectStandbyNetwork(tt.state, tt.setting)
+			got := ""
+			if len(findings) > 0 {
+				got = findings[0].Title
+				if findings[0].Kind != findingStandbyNetwork || len(findings) != 1 {
+					t.Errorf("findings = %+v", findings)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("title = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSystemFindings(t *testing.T) {
+	scheme := &PowerScheme{Subgroups: []PowerSubgroup{
+		{GUID: subgroupSleep, Settings: []PowerSetting{{GUID: networkStandbySetting, AC: 1, HasAC: true}}},
+		{GUID: subgroupNone, Alias: "SUB_NONE", Settings: []PowerSetting{{GUID: networkStandbySetting, AC: 0, DC: 2, HasAC: true, HasDC: true}}},
+	}}
+	// The setting is looked up in "no subgroup" only
+	findings := systemFindings(ModernStandbyState{S0Available: true}, scheme)
+	if len(findings) != 1 || findings[0].Title != "Netzwerkverbindung im Modern Standby aktiv (Netzbetrieb: disabled, Batterie: managed)" {
+		t.Errorf("findings = %+v", findings)
+	}
+	if findings := systemFindings(ModernStandbyState{S0Available: true}, &PowerScheme{}); findings != nil {
+		t.Errorf("findings without the setting = %+v", findings)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package main
+
+// SleepRight only runs on Windows. These stubs let the platform-independent logic
+// (parsers, profile comparison, enforcement loop, event catalog) build and be
+// tested on other systems.
+
+import (
+	"errors"
+	"net"
+)
+
+var errNotWindows = errors.New("requires Windows")
+
+func dialPipe(name string) (net.Conn, error)                           { return nil, errNotWindows }
+func listenPipe(name string) (net.Listener, error)                     { return nil, errNotWindows }
+func runElevated(exe, args, dir string) error                          { return errNotWindows }
+func wmiQuery(query string, dst interface{}) error                     { return errNotWindows }
+func wmiQueryNamespace(query string, dst interface{}, ns string) error { return errNotWindows }
+func consoleIsUTF8() bool                                              { return true }
+func consoleWidth() int                                                { return 80 }
+func keepSystemAwake(awake bool)                                       {}
+func eventSourceInstalled() bool                                       { return false }
+func writeEvent(event Event)                                           {}
+func setEventSource(enabled bool) error                                { return errNotWindows }
+func runService() error                                                { return errNotWindows }
+func installService() error                                            { return errNotWindows }
+func uninstallService() error                                          { return errNotWindows }
+func runWatch(allowed wakeDeviceSet) error                             { return errNotWindows }
+
+// windowsRegistry is the registryStore backed by the Windows registry
+type windowsRegistry struct{}
+
+func (windowsRegistry) GetDWORD(path, name string) (uint32, bool, error) {
+	return 0, false, errNotWindows
+}
+func (windowsRegistry) SetDWORD(path, name string, value uint32) error { return errNotWindows }
+func (windowsRegistry) DeleteValue(path, name string) error            { return errNotWindows }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net"
+	"syscall"
+
+	"github.com/Microsoft/go-winio"
+	"github.com/yusufpapurcu/wmi"
+	"golang.org/x/sys/windows"
+)
+
+// dialPipe connects to the named pipe of the parent process
+func dialPipe(name string) (net.Conn, error) {
+	return winio.DialPipe(name, nil)
+}
+
+// listenPipe creates the named pipe the elevated instance writes its output to
+func listenPipe(name string) (net.Listener, error) {
+	return winio.ListenPipe(name, nil)
+}
+
+// runElevated starts exe with administrator rights through ShellExecute ("runas")
+func runElevated(exe, args, dir string) error {
+	verbPtr, _ := syscall.UTF16PtrFromString("runas")
+	exePtr, _ := syscall.UTF16PtrFromString(exe)
+	argsPtr, _ := syscall.UTF16PtrFromString(args)
+	var dirPtr *uint16
+	if dir != "" {
+		dirPtr, _ = syscall.UTF16PtrFromString(dir)
+	}
+
+	// showCmd := int32(0) // SW_HIDE - hide the window
+	showCmd := int32(1) // SW_NORMAL - show window for debugging
+
+	return windows.ShellExecute(0, verbPtr, exePtr, argsPtr, dirPtr, showCmd)
+}
+
+// wmiQuery runs a WMI query in the default namespace root\cimv2
+func wmiQuery(query string, dst interface{}) error {
+	return wmi.Query(query, dst)
+}
+
+// wmiQueryNamespace runs a WMI query in the given namespace
+func wmiQueryNamespace(query string, dst interface{}, namespace string) error {
+	return wmi.QueryNamespace(query, dst, namespace)
+}
+
+// consoleIsUTF8 reports whether the console output codepage is UTF-8 (65001)
+func consoleIsUTF8() bool {
+	cp, err := windows.GetConsoleOutputCP()
+	return err == nil && cp == 65001
+}
+
+// consoleWidth returns the width of the console window in columns (80 if unknown)
+func consoleWidth() int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Stdout, &info); err != nil {
+		return 80
+	}
+	width := int(info.Window.Right-info.Window.Left) + 1
+	if width <= 0 {
+		return 80
+	}
+	return width
+}
+
+// keepSystemAwake prevents (or allows again) sleep while the process runs
+func keepSystemAwake(awake bool) {
+	const esContinuous, esSystemRequired = 0x80000000, 0x00000001
+	flags := uintptr(esContinuous)
+	if awake {
+		flags |= esSystemRequired
+	}
+	windows.NewLazySystemDLL("kernel32.dll").NewProc("SetThreadExecutionState").Call(flags)
+}
@@ -2,13 +2,10 @@ package main
 
 import (
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/yusufpapurcu/wmi"
 )
 
 func showPowerSettings(full bool) error {
@@ -18,50 +15,53 @@ func showPowerSettings(full bool) error {
 		return fmt.Errorf("Fehler beim Abrufen des aktiven Energieschemas: %w", err)
 	}
 	printUTF8ln("Aktives Energieschema:")
-	printUTF8ln(outputStr)
+	printUTF8ln("%s", outputStr)
 
-	// Get sleep timeout settings
-	if err := showSleepSettings(); err != nil {
-		return fmt.Errorf("Fehler beim Anzeigen der Ruhezustand-Einstellungen: %w", err)
+	// Get sleep, hibernate and other catalog settings
+	if err := showCatalogSettings(); err != nil {
+		return fmt.Errorf("Fehler beim Anzeigen der Energieeinstellungen: %w", err)
 	}
 
-	// Get hibernate timeout settings
-	if err := showHibernateSettings(); err != nil {
-		return fmt.Errorf("Fehler beim Anzeigen der Ruhezustand-Einstellungen: %w", err)
+	// Hibernation feature and hibernate file
+	if err := showHibernationState(); err != nil {
+		return fmt.Errorf("Fehler beim Anzeigen des Ruhezustands: %w", err)
 	}
 
-	// Get wake device settings
-	if err := showWakeDeviceSettings(full); err != nil {
-		return fmt.Errorf("Fehler beim Anzeigen der Aufweck-Geräte-Einstellungen: %w", err)
+	// Fast Startup
+	if err := showFastStartup(); err != nil {
+		return fmt.Errorf("Fehler beim Anzeigen des Schnellstarts: %w", err)
 	}
 
-	return nil
-}
-
-func showSleepSettings() error {
-	outputStr, err := runCommandWithEncoding("powercfg", "/query", "SCHEME_CURRENT", "SUB_SLEEP", "STANDBYIDLE")
-	if err != nil {
-		return fmt.Errorf("Fehler beim Abrufen der Ruhezustand-Einstellungen: %w", err)
+	// Modern Standby and S3 support
+	if err := showModernStandby(); err != nil {
+		return fmt.Errorf("Fehler beim Anzeigen von Modern Standby: %w", err)
 	}
 
-	printUTF8ln("\nRuhezustand-Einstellungen (Netzbetrieb):")
-	parsePowerSetting(outputStr, "AC Setting Index")
+	// Settings hidden in the Control Panel
+	if full {
+		if err := showHiddenSettings(); err != nil {
+			return fmt.Errorf("Fehler beim Anzeigen der ausgeblendeten Einstellungen: %w", err)
+		}
+	}
 
-	printUTF8ln("\nRuhezustand-Einstellungen (Batterie):")
-	parsePowerSetting(outputStr, "DC Setting Index")
+	// Get wake device settings
+	if err := showWakeDeviceSettings(full); err != nil {
+		return fmt.Errorf("Fehler beim Anzeigen der Aufweck-Geräte-Einstellungen: %w", err)
+	}
 
 	return nil
 }
 
-func showHibernateSettings() error {
-	outputStr, err := runCommandWithEncoding("powercfg", "/query", "SCHEME_CURRENT", "SUB_SLEEP", "HIBERNATEIDLE")
-	if err != nil {
-		return fmt.Errorf("Fehler beim Abrufen der Ruhezustand-Einstellungen: %w", err)
+// systemHasBattery reports whether the computer has a battery (laptop or tablet)
+func systemHasBattery() bool {
+	type Win32_Battery struct {
+		Name string
 	}
-
-	printUTF8ln("\nRuhezustand-Einstellungen:")
-	parsePowerSetting(outputStr, "Setting Index")
-	return nil
+	var batteries []Win32_Battery
+	if err := wmiQuery("SELECT Name FROM Win32_Battery", &batteries); err != nil {
+		return false
+	}
+	return len(batteries) > 0
 }
 
 // WMINetworkWakeInfo represents WMI information about network wake settings
@@ -72,17 +72,27 @@ type WMINetworkWakeInfo struct {
 	EnableWakeOnMagicPacketOnly bool   `wmi:"EnableWakeOnMagicPacketOnly"`
 }
 
-func showWakeDeviceSettings(full bool) error {
+// WakeDevice is a wake-programmable device and its current wake configuration
+type WakeDevice struct {
+	Name            string
+	Armed           bool // device is currently allowed to wake the system
+	IsNetwork       bool // WMI magic packet information is available
+	MagicPacketOnly bool
+}
+
+// collectWakeDevices returns all wake-programmable devices with their wake state and
+// the number of currently wake-armed devices
+func collectWakeDevices() ([]WakeDevice, int, error) {
 	// Get all devices that are currently wake-armed
 	armedOutput, err := runCommandWithEncoding("powercfg", "/devicequery", "wake_armed")
 	if err != nil {
-		return fmt.Errorf("failed to get wake-armed devices: %w", err)
+		return nil, 0, fmt.Errorf("failed to get wake-armed devices: %w", err)
 	}
 
 	// Get all devices that are wake-programmable (can potentially wake)
 	programmableOutput, err := runCommandWithEncoding("powercfg", "/devicequery", "wake_programmable")
 	if err != nil {
-		return fmt.Errorf("failed to get wake-programmable devices: %w", err)
+		return nil, 0, fmt.Errorf("failed to get wake-programmable devices: %w", err)
 	}
 
 	// Parse wake-armed devices into a map for quick lookup
@@ -109,7 +119,7 @@ func showWakeDeviceSettings(full bool) error {
 	var networkWakeInfo []WMINetworkWakeInfo
 	const NameSpace = "root\\wmi"
 
-	err = wmi.QueryNamespace("SELECT InstanceName, Active, EnableWakeOnMagicPacketOnly FROM MSNdis_DeviceWakeOnMagicPacketOnly", &networkWakeInfo, NameSpace)
+	err = wmiQueryNamespace("SELECT InstanceName, Active, EnableWakeOnMagicPacketOnly FROM MSNdis_DeviceWakeOnMagicPacketOnly", &networkWakeInfo, NameSpace)
 	if err != nil {
 		// WMI query failed, continue without WMI info
 		if verboseFlag {
@@ -125,7 +135,7 @@ func showWakeDeviceSettings(full bool) error {
 	idToName := make(map[string]string)
 
 	// Win32_NetworkAdapter liegt im Standard-Namespace root\cimv2
-	err = wmi.Query("SELECT Name, PNPDeviceID FROM Win32_NetworkAdapter", &adapters)
+	err = wmiQuery("SELECT Name, PNPDeviceID FROM Win32_NetworkAdapter", &adapters)
 	if err == nil {
 		for _, a := range adapters {
 			idToName[strings.ToLower(a.PNPDeviceID)] = a.Name
@@ -180,11 +190,30 @@ func showWakeDeviceSettings(full bool) error {
 		return WMINetworkWakeInfo{}, false
 	}
 
+	var devices []WakeDevice
+	for _, name := range programmableDevices {
+		device := WakeDevice{Name: name, Armed: armedDevices[name]}
+		if wmiInfo, found := findWMIInfo(name); found {
+			device.IsNetwork = true
+			device.MagicPacketOnly = wmiInfo.EnableWakeOnMagicPacketOnly
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, len(armedDevices), nil
+}
+
+func showWakeDeviceSettings(full bool) error {
+	devices, armedCount, err := collectWakeDevices()
+	if err != nil {
+		return err
+	}
+
 	// Separate devices into enabled and disabled
-	var enabledDevices []string
-	var disabledDevices []string
-	for _, device := range programmableDevices {
-		if armedDevices[device] {
+	var enabledDevices []WakeDevice
+	var disabledDevices []WakeDevice
+	for _, device := range devices {
+		if device.Armed {
 			enabledDevices = append(enabledDevices, device)
 		} else {
 			disabledDevices = append(disabledDevices, device)
@@ -194,21 +223,21 @@ func showWakeDeviceSettings(full bool) error {
 	// Display results
 	printUTF8ln("\n=== Aufweck-Geräte-Analyse ===")
 	if full {
-		printUTF8ln("\nGesamt aufweck-programmierbare Geräte: %d", len(programmableDevices))
-		printUTF8ln("Aktuell aufweck-aktivierte Geräte: %d\n", len(armedDevices))
+		printUTF8ln("\nGesamt aufweck-programmierbare Geräte: %d", len(devices))
+		printUTF8ln("Aktuell aufweck-aktivierte Geräte: %d\n", armedCount)
 	} else {
-		printUTF8ln("\nAktuell aufweck-aktivierte Geräte: %d\n", len(armedDevices))
+		printUTF8ln("\nAktuell aufweck-aktivierte Geräte: %d\n", armedCount)
 	}
 
 	// Display enabled devices first
 	if len(enabledDevices) > 0 {
 		printUTF8ln("Aktivierte aufweck-programmierbare Geräte:")
 		for i, device := range enabledDevices {
-			printUTF8("  %d. %s", i+1, device)
+			printUTF8("  %d. %s", i+1, device.Name)
 
 			// Check if this is a network device with WMI info
-			if wmiInfo, found := findWMIInfo(device); found {
-				if wmiInfo.EnableWakeOnMagicPacketOnly {
+			if device.IsNetwork {
+				if device.MagicPacketOnly {
 					printUTF8(" - Magic-Packet: Aktiviert")
 				} else {
 					printUTF8(" - Magic-Packet: Deaktiviert")
@@ -225,11 +254,11 @@ func showWakeDeviceSettings(full bool) error {
 		}
 		printUTF8ln("Deaktivierte aufweck-programmierbare Geräte:")
 		for i, device := range disabledDevices {
-			printUTF8("  %d. %s", i+1, device)
+			printUTF8("  %d. %s", i+1, device.Name)
 
 			// Always show Magic-Packet status for disabled network devices
-			if wmiInfo, found := findWMIInfo(device); found {
-				if wmiInfo.EnableWakeOnMagicPacketOnly {
+			if device.IsNetwork {
+				if device.MagicPacketOnly {
 					printUTF8(" - Magic-Packet: Aktiviert")
 				} else {
 					printUTF8(" - Magic-Packet: Deaktiviert")
@@ -239,14 +268,25 @@ func showWakeDeviceSettings(full bool) error {
 		}
 	}
 
-	if len(programmableDevices) == 0 {
+	if len(devices) == 0 {
 		printUTF8ln("Keine aufweck-programmierbaren Geräte gefunden.")
 	}
 
 	return nil
 }
 
-func parsePowerSetting(output, searchKey string) {
+// formatTimeout formats a timeout in seconds, 0 meaning disabled
+func formatTimeout(seconds int) string {
+	if seconds == 0 {
+		return "Deaktiviert"
+	}
+	return formatDuration(time.Duration(seconds) * time.Second)
+}
+
+// parsePowerSettingValue extracts the current AC or DC value (in seconds for timeouts)
+// from powercfg /query output. The searchKey selects AC ("AC Setting Index"), DC
+// ("DC Setting Index") or the first current value ("Setting Index").
+func parsePowerSettingValue(output, searchKey string) (int, bool) {
 	lines := strings.Split(output, "\n")
 
 	// Determine if we're looking for AC or DC setting based on searchKey
@@ -281,16 +321,7 @@ func parsePowerSetting(output, searchKey string) {
 					// Parse hex value
 					value, err := strconv.ParseInt(matches[1], 16, 64)
 					if err == nil {
-						// Value is in seconds, use formatDuration for consistent formatting
-						seconds := int(value)
-						if seconds == 0 {
-							printUTF8ln("  Timeout: Deaktiviert")
-						} else {
-							duration := time.Duration(seconds) * time.Second
-							timeoutStr := formatDuration(duration)
-							printUTF8ln("  Timeout: %s", timeoutStr)
-						}
-						return
+						return int(value), true
 					}
 				}
 
@@ -300,14 +331,7 @@ func parsePowerSetting(output, searchKey string) {
 				if len(matches2) > 1 {
 					value, err := strconv.Atoi(matches2[1])
 					if err == nil {
-						if value == 0 {
-							printUTF8ln("  Timeout: Deaktiviert")
-						} else {
-							duration := time.Duration(value) * time.Second
-							timeoutStr := formatDuration(duration)
-							printUTF8ln("  Timeout: %s", timeoutStr)
-						}
-						return
+						return value, true
 					}
 				}
 			}
@@ -342,15 +366,7 @@ func parsePowerSetting(output, searchKey string) {
 					if len(matches) > 1 {
 						value, err := strconv.ParseInt(matches[1], 16, 64)
 						if err == nil {
-							seconds := int(value)
-							if seconds == 0 {
-								printUTF8ln("  Timeout: Deaktiviert")
-							} else {
-								duration := time.Duration(seconds) * time.Second
-								timeoutStr := formatDuration(duration)
-								printUTF8ln("  Timeout: %s", timeoutStr)
-							}
-							return
+							return int(value), true
 						}
 					}
 
@@ -360,14 +376,7 @@ func parsePowerSetting(output, searchKey string) {
 					if len(matches2) > 1 {
 						value, err := strconv.Atoi(matches2[1])
 						if err == nil && value >= 0 && value <= 86400 {
-							if value == 0 {
-								printUTF8ln("  Timeout: Deaktiviert")
-							} else {
-								duration := time.Duration(value) * time.Second
-								timeoutStr := formatDuration(duration)
-								printUTF8ln("  Timeout: %s", timeoutStr)
-							}
-							return
+							return value, true
 						}
 					}
 				}
@@ -375,229 +384,209 @@ func parsePowerSetting(output, searchKey string) {
 		}
 	}
 
-	// If still not found, show debug info or default message
-	if debugFlag {
-		printUTF8ln("  Einstellung nicht gefunden (Suchschlüssel: %s)", searchKey)
-		printUTF8ln("  Erste 30 Zeilen der Ausgabe zum Debuggen:")
-		for i, line := range lines {
-			if i >= 30 {
-				break
-			}
-			printUTF8ln("    %d: %s", i, strings.TrimSpace(line))
-		}
-	} else {
-		printUTF8ln("  Timeout: Nicht konfiguriert oder nicht verfügbar")
-	}
+	return 0, false
 }
 
-func configureWakeDevices() error {
+// configureWakeDevices disarms all wake devices and then arms the keyboard and the
+// network adapters again if the profile allows them. Every device is a step of its
+// own, so one failing device does not stop the others.
+func configureWakeDevices(allowed wakeDeviceSet) []StepResult {
 	fmt.Println("Configuring wake devices...")
+	var results stepResults
 
 	// First, disable all wake devices
-	cmd := exec.Command("powercfg", "/devicequery", "wake_armed")
-	output, err := cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				// Disable wake for this device
-				cmd := exec.Command("powercfg", "/devicedisablewake", line)
-				if err := cmd.Run(); err != nil {
-					if verboseFlag {
-						fmt.Printf("  Warning: Could not disable wake for %s: %v\n", line, err)
-					}
-				} else {
-					if verboseFlag {
-						fmt.Printf("  Disabled wake for: %s\n", line)
-					}
-				}
-			}
+	armed, err := (powercfgProbe{}).WakeArmed()
+	if err != nil {
+		results.add("disable wake", "wake-armed devices", err)
+		return results
+	}
+	// Devices still armed because disarming them failed
+	stillArmed := make(map[string]bool)
+	for _, device := range armed {
+		change := auditChange{Action: "device-wake", Target: device, Previous: "armed", New: "disarmed"}
+		if results.add("disable wake", device, runAudited(change, "powercfg", "/devicedisablewake", device)) != stepApplied {
+			stillArmed[device] = true
+		} else if verboseFlag {
+			fmt.Printf("  Disabled wake for: %s\n", device)
 		}
 	}
 
-	// Enable wake for keyboard
-	fmt.Println("  Enabling wake for keyboard...")
-	cmd = exec.Command("powercfg", "/deviceenablewake", "\"HID Keyboard Device\"")
-	if err := cmd.Run(); err != nil {
-		// Try alternative names
-		cmd = exec.Command("powercfg", "/deviceenablewake", "\"Standard PS/2 Keyboard\"")
-		if err := cmd.Run(); err != nil {
-			// Try to find keyboard device
-			cmd = exec.Command("powercfg", "/devicequery", "wake_programmable")
-			output, err := cmd.Output()
-			if err == nil {
-				lines := strings.Split(string(output), "\n")
-				for _, line := range lines {
-					line = strings.TrimSpace(line)
-					if strings.Contains(strings.ToLower(line), "keyboard") {
-						cmd = exec.Command("powercfg", "/deviceenablewake", line)
-						cmd.Run()
-						break
-					}
-				}
+	enableWake := func(device string) error {
+		previous := "disarmed"
+		if stillArmed[device] {
+			previous = "armed"
+		}
+		return runAudited(auditChange{Action: "device-wake", Target: device, Previous: previous, New: "armed"}, "powercfg", "/deviceenablewake", device)
+	}
+	programmable, err := queryWakeProgrammable()
+	if err != nil {
+		results.add("enable wake", "wake-programmable devices", err)
+		return results
+	}
+
+	if allowed.Keyboard {
+		// Enable wake for keyboard: the common names first, then any keyboard. Only
+		// wake-programmable devices are tried, so a missing device does not end up in
+		// the audit log as failed change.
+		fmt.Println("  Enabling wake for keyboard...")
+		keyboard := ""
+		var keyboardErr error
+		for _, candidate := range keyboardWakeCandidates(programmable) {
+			if keyboardErr = enableWake(candidate); keyboardErr == nil {
+				keyboard = candidate
+				break
 			}
 		}
+		switch {
+		case keyboard != "":
+			results.add("enable wake", keyboard, nil)
+		case keyboardErr != nil:
+			results.add("enable wake", "keyboard", keyboardErr)
+		default:
+			results.add("enable wake", "keyboard", fmt.Errorf("keyboard not found among the wake-programmable devices"))
+		}
 	}
 
-	// Enable wake for Ethernet adapter
-	fmt.Println("  Enabling wake for Ethernet adapter...")
-	cmd = exec.Command("powercfg", "/devicequery", "wake_programmable")
-	output, err = cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if strings.Contains(strings.ToLower(line), "ethernet") ||
-				strings.Contains(strings.ToLower(line), "network") ||
-				strings.Contains(strings.ToLower(line), "realtek") ||
-				strings.Contains(strings.ToLower(line), "intel") {
-				cmd = exec.Command("powercfg", "/deviceenablewake", line)
-				if err := cmd.Run(); err != nil {
-					if verboseFlag {
-						fmt.Printf("    Warning: Could not enable wake for %s: %v\n", line, err)
-					}
-				} else {
-					fmt.Printf("    Enabled wake for: %s\n", line)
-				}
+	if allowed.Network {
+		// Enable wake for Ethernet adapter
+		fmt.Println("  Enabling wake for Ethernet adapter...")
+		found := false
+		for _, device := range programmable {
+			if !isNetworkWakeDevice(device) {
+				continue
 			}
+			found = true
+			if results.add("enable wake", device, enableWake(device)) == stepApplied {
+				fmt.Printf("    Enabled wake for: %s\n", device)
+			}
+		}
+		if !found {
+			results.add("enable wake", "network adapter", skipped("no wake-programmable network adapter"))
 		}
 	}
 
 	fmt.Println("  Wake device configuration completed.")
-	return nil
+	return results
 }
 
-func configureSleepTimeout() error {
-	fmt.Println("Configuring sleep timeout to 30 minutes...")
-
-	// Set sleep timeout to 30 minutes (1800 seconds) for AC power
-	cmd := exec.Command("powercfg", "/change", "standby-timeout-ac", "30")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set AC sleep timeout: %w", err)
+// isNetworkWakeDevice reports whether a device is a network adapter that
+// -configure allows to wake the computer
+func isNetworkWakeDevice(name string) bool {
+	lower := strings.ToLower(name)
+	for _, keyword := range []string{"ethernet", "network", "realtek", "intel"} {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Set sleep timeout to 30 minutes for battery
-	cmd = exec.Command("powercfg", "/change", "standby-timeout-dc", "30")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set battery sleep timeout: %w", err)
-	}
+// isKeyboardWakeDevice reports whether a device is a keyboard
+func isKeyboardWakeDevice(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "keyboard") || strings.Contains(lower, "tastatur")
+}
 
-	fmt.Println("  Sleep timeout set to 30 minutes.")
-	return nil
+// wakeDeviceSet is the kind of devices a profile allows to wake the computer
+// ("wake-devices"). -configure arms them, check and watch report all other devices.
+type wakeDeviceSet struct {
+	Keyboard bool
+	Network  bool
 }
 
-func configurePowerScheme() error {
-	fmt.Println("Configuring power scheme to Balanced...")
+// Allows reports whether a wake-armed device belongs to the set
+func (s wakeDeviceSet) Allows(device string) bool {
+	return (s.Keyboard && isKeyboardWakeDevice(device)) || (s.Network && isNetworkWakeDevice(device))
+}
 
-	// Get available power schemes
-	cmd := exec.Command("powercfg", "/list")
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("failed to list power schemes: %w", err)
-	}
-
-	// Find Balanced scheme GUID
-	lines := strings.Split(string(output), "\n")
-	var balancedGUID string
-	for _, line := range lines {
-		if strings.Contains(line, "Balanced") {
-			// Extract GUID (format: * GUID: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx)
-			re := regexp.MustCompile(`([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`)
-			matches := re.FindStringSubmatch(line)
-			if len(matches) > 1 {
-				balancedGUID = matches[1]
-				break
-			}
+// keyboardWakeCandidates returns the wake-programmable keyboards in the order they are
+// tried: "HID Keyboard Device" and "Standard PS/2 Keyboard" first, then any other
+// device that looks like a keyboard
+func keyboardWakeCandidates(programmable []string) []string {
+	var preferred, others []string
+	for _, device := range programmable {
+		switch {
+		case strings.EqualFold(device, "HID Keyboard Device") || strings.EqualFold(device, "Standard PS/2 Keyboard"):
+			preferred = append(preferred, device)
+		case isKeyboardWakeDevice(device):
+			others = append(others, device)
 		}
 	}
-
-	if balancedGUID == "" {
-		return fmt.Errorf("could not find Balanced power scheme")
-	}
-
-	// Set active scheme to Balanced
-	cmd = exec.Command("powercfg", "/setactive", balancedGUID)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set active power scheme: %w", err)
-	}
-
-	fmt.Printf("  Power scheme set to Balanced (%s).\n", balancedGUID)
-	return nil
+	return append(preferred, others...)
 }
 
-func configureHibernateTimeout(minutes int) error {
-	fmt.Printf("Configuring hibernate timeout to %d minutes...\n", minutes)
-
-	// Set hibernate timeout for AC power (powercfg expects minutes)
-	cmd := exec.Command("powercfg", "/change", "hibernate-timeout-ac", strconv.Itoa(minutes))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set AC hibernate timeout: %w", err)
+// queryWakeProgrammable lists the devices that can be armed for wake
+func queryWakeProgrammable() ([]string, error) {
+	output, err := runCommandWithEncoding("powercfg", "/devicequery", "wake_programmable")
+	if err != nil {
+		return nil, err
 	}
-
-	// Set hibernate timeout for battery (powercfg expects minutes)
-	cmd = exec.Command("powercfg", "/change", "hibernate-timeout-dc", strconv.Itoa(minutes))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set battery hibernate timeout: %w", err)
+	var devices []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			devices = append(devices, line)
+		}
 	}
-
-	fmt.Printf("  Hibernate timeout set to %d minutes.\n", minutes)
-	return nil
+	return devices, nil
 }
 
-// configureWakeTimers disables wake timers to prevent scheduled tasks from waking the system
-func configureWakeTimers() error {
-	fmt.Println("Configuring wake timers (disabling scheduled task wake-ups)...")
+// Name and description of the power scheme managed by SleepRight
+const (
+	sleepRightSchemeName        = "SleepRight"
+	sleepRightSchemeDescription = "Managed by SleepRight - manual changes may be overwritten"
+)
+
+// configurePowerScheme activates the SleepRight power scheme. It is created as a copy
+// of Balanced on the first run, so the stock schemes stay untouched; later runs find
+// and reuse the existing scheme.
+func configurePowerScheme() error {
+	fmt.Printf("Configuring power scheme %s...\n", sleepRightSchemeName)
 
-	// Get current power scheme GUID
-	cmd := exec.Command("powercfg", "/getactivescheme")
-	output, err := cmd.Output()
+	// Get available power schemes
+	schemes, err := listPowerSchemes()
 	if err != nil {
-		return fmt.Errorf("failed to get active power scheme: %w", err)
+		return err
 	}
 
-	// Extract GUID from output
-	re := regexp.MustCompile(`([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`)
-	matches := re.FindStringSubmatch(string(output))
-	if len(matches) < 2 {
-		return fmt.Errorf("could not extract power scheme GUID")
+	// Look for an existing SleepRight scheme
+	var schemeGUID string
+	if existing := findSchemeEntry(schemes, sleepRightSchemeName); existing != nil {
+		schemeGUID = existing.GUID
 	}
-	schemeGUID := matches[1]
 
-	// Disable wake timers for AC power
-	// Setting ID: 238c9fa8-0aad-41ed-83f4-97be242c8f20 (Sleep)
-	// Subgroup ID: 29f6c1db-86da-48c5-9fdb-f2b67b1f44da (Sleep)
-	// Setting ID: bd3b718a-0680-4d9d-8ab2-e1d2b4ac806d (Allow wake timers)
-	// Value: 0 = Disabled, 1 = Enabled (AC), 2 = Enabled (DC), 3 = Enabled (Both)
+	if schemeGUID == "" {
+		// Balanced is identified by its well-known GUID, its name depends on the UI language
+		if findSchemeEntry(schemes, balancedSchemeGUID) == nil {
+			return fmt.Errorf("could not find Balanced power scheme (%s)", balancedSchemeGUID)
+		}
 
-	// For AC power: set to 0 (Disabled)
-	cmd = exec.Command("powercfg", "/setacvalueindex", schemeGUID,
-		"238c9fa8-0aad-41ed-83f4-97be242c8f20",
-		"29f6c1db-86da-48c5-9fdb-f2b67b1f44da",
-		"bd3b718a-0680-4d9d-8ab2-e1d2b4ac806d", "0")
-	if err := cmd.Run(); err != nil {
-		if verboseFlag {
-			fmt.Printf("  Warning: Could not disable AC wake timers: %v\n", err)
+		// Duplicate Balanced; powercfg prints the GUID of the new scheme
+		change := auditChange{Action: "scheme", Target: balancedSchemeGUID, New: "duplicated"}
+		output, err := runAuditedOutput(change, "powercfg", "/duplicatescheme", balancedSchemeGUID)
+		if err != nil {
+			return fmt.Errorf("failed to duplicate Balanced power scheme: %w", err)
 		}
-	}
+		matches := guidPattern.FindStringSubmatch(output)
+		if len(matches) < 2 {
+			return fmt.Errorf("could not extract GUID of the duplicated power scheme")
+		}
+		schemeGUID = matches[1]
 
-	// For DC (battery) power: set to 0 (Disabled)
-	cmd = exec.Command("powercfg", "/setdcvalueindex", schemeGUID,
-		"238c9fa8-0aad-41ed-83f4-97be242c8f20",
-		"29f6c1db-86da-48c5-9fdb-f2b67b1f44da",
-		"bd3b718a-0680-4d9d-8ab2-e1d2b4ac806d", "0")
-	if err := cmd.Run(); err != nil {
-		if verboseFlag {
-			fmt.Printf("  Warning: Could not disable DC wake timers: %v\n", err)
+		change = auditChange{Action: "scheme", Target: schemeGUID, New: sleepRightSchemeName}
+		if err := runAudited(change, "powercfg", "/changename", schemeGUID, sleepRightSchemeName, sleepRightSchemeDescription); err != nil {
+			return fmt.Errorf("failed to rename power scheme %s: %w", schemeGUID, err)
 		}
+		fmt.Printf("  Created power scheme %s (%s) from Balanced.\n", sleepRightSchemeName, schemeGUID)
+	} else if verboseFlag {
+		fmt.Printf("  Found existing power scheme %s (%s).\n", sleepRightSchemeName, schemeGUID)
 	}
 
-	// Apply the changes
-	cmd = exec.Command("powercfg", "/setactive", schemeGUID)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to apply wake timer settings: %w", err)
+	// Set active scheme to SleepRight
+	change := auditChange{Action: "scheme", Target: schemeGUID, Previous: activeSchemeGUID(schemes), New: "active"}
+	if err := runAudited(change, "powercfg", "/setactive", schemeGUID); err != nil {
+		return fmt.Errorf("failed to set active power scheme: %w", err)
 	}
 
-	fmt.Println("  Wake timers disabled (scheduled tasks will not wake the system).")
+	fmt.Printf("  Power scheme set to %s (%s).\n", sleepRightSchemeName, schemeGUID)
 	return nil
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyboardWakeCandidates(t *testing.T) {
+	tests := []struct {
+		name         string
+		programmable []string
+		want         []string
+	}{
+		{"none", []string{"Intel(R) Ethernet Connection I219-V", "HID-compliant mouse"}, nil},
+		{"common names first", []string{"Logitech USB Keyboard", "Intel(R) Ethernet Connection I219-V", "HID Keyboard Device"},
+			[]string{"HID Keyboard Device", "Logitech USB Keyboard"}},
+		{"PS/2 and German names", []string{"HID-Tastatur", "Standard PS/2 Keyboard"},
+			[]string{"Standard PS/2 Keyboard", "HID-Tastatur"}},
+		{"only other keyboards", []string{"HID-Tastatur"}, []string{"HID-Tastatur"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keyboardWakeCandidates(tt.programmable)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("keyboardWakeCandidates = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PowerScheme is the parsed output of "powercfg /query <scheme>": every subgroup and
+// setting of the scheme with its possible values and current AC/DC index
+type PowerScheme struct {
+	GUID      string
+	Alias     string
+	Name      string
+	Subgroups []PowerSubgroup
+}
+
+// PowerSubgroup is a subgroup of a power scheme (e.g. SUB_SLEEP)
+type PowerSubgroup struct {
+	GUID     string
+	Alias    string
+	Name     string
+	Settings []PowerSetting
+}
+
+// PowerSetting is a single power setting with its possible values and current indices.
+// Settings either list possible values (Options) or a numeric range (HasRange).
+type PowerSetting struct {
+	GUID      string
+	Alias     string
+	Name      string
+	Options   []PowerSettingOption
+	HasRange  bool
+	Min       uint32
+	Max       uint32
+	Increment uint32
+	Units     string
+	AC, DC    uint32
+	HasAC     bool
+	HasDC     bool
+}
+
+// PowerSettingOption is a possible value of an enumerated power setting
+type PowerSettingOption struct {
+	Index uint32
+	Name  string
+}
+
+var (
+	guidPattern     = regexp.MustCompile(`([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})(?:\s+\((.*)\))?`)
+	hexValuePattern = regexp.MustCompile(`^0x([0-9a-fA-F]+)$`)
+	optionPattern   = regexp.MustCompile(`^[0-9]+$`)
+	aliasPattern    = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// GUIDs of the power schemes shipped with Windows. They are identical in every UI
+// language, unlike the scheme names.
+const (
+	balancedSchemeGUID        = "381b4222-f694-41f0-9685-ff5bb260df2e"
+	highPerformanceSchemeGUID = "8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c"
+	powerSaverSchemeGUID      = "a1841308-3541-4fab-bc81-f71556f20b4a"
+	ultimateSchemeGUID        = "e9a42b02-d5df-448d-aa00-03f14749eb61"
+)
+
+// builtinSchemes maps the GUIDs of the stock schemes to names that can be used on the
+// command line in every UI language
+var builtinSchemes = map[string]string{
+	balancedSchemeGUID:        "balanced",
+	highPerformanceSchemeGUID: "high-performance",
+	powerSaverSchemeGUID:      "power-saver",
+	ultimateSchemeGUID:        "ultimate",
+}
+
+// builtinSchemeGUID returns the GUID of a stock scheme given by its
+// language-independent name, or "" if name is not one
+func builtinSchemeGUID(name string) string {
+	for guid, builtin := range builtinSchemes {
+		if strings.EqualFold(builtin, name) {
+			return guid
+		}
+	}
+	return ""
+}
+
+// PowerSchemeEntry is a power scheme as listed by "powercfg /list"
+type PowerSchemeEntry struct {
+	GUID    string
+	Name    string // localized name
+	Builtin string // language-independent name of a stock scheme, empty otherwise
+	Active  bool
+}
+
+// listPowerSchemes runs powercfg /list and parses the output
+func listPowerSchemes() ([]PowerSchemeEntry, error) {
+	output, err := runCommandWithEncoding("powercfg", "/list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list power schemes: %w", err)
+	}
+	return parsePowerSchemeList(output), nil
+}
+
+// parsePowerSchemeList parses "powercfg /list" output. Every line with a GUID is a
+// scheme (format: "Power Scheme GUID: <guid>  (<name>) *"); the trailing asterisk
+// marks the active scheme. The labels are localized and therefore ignored.
+func parsePowerSchemeList(output string) []PowerSchemeEntry {
+	var schemes []PowerSchemeEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		m := guidPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		guid := strings.ToLower(m[1])
+		schemes = append(schemes, PowerSchemeEntry{
+			GUID:    guid,
+			Name:    decodeCP1252(strings.TrimSpace(m[2])),
+			Builtin: builtinSchemes[guid],
+			Active:  strings.HasSuffix(line, "*"),
+		})
+	}
+	return schemes
+}
+
+// activeSchemeGUID returns the GUID of the listed scheme marked as active, empty if
+// none is marked
+func activeSchemeGUID(schemes []PowerSchemeEntry) string {
+	for _, scheme := range schemes {
+		if scheme.Active {
+			return scheme.GUID
+		}
+	}
+	return ""
+}
+
+// findSchemeEntry returns the listed scheme with the given GUID, (case-insensitive)
+// name or language-independent name of a stock scheme, or nil
+func findSchemeEntry(schemes []PowerSchemeEntry, guidOrName string) *PowerSchemeEntry {
+	if guid := builtinSchemeGUID(guidOrName); guid != "" {
+		guidOrName = guid
+	}
+	for i := range schemes {
+		if strings.EqualFold(schemes[i].GUID, guidOrName) || strings.EqualFold(schemes[i].Name, guidOrName) {
+			return &schemes[i]
+		}
+	}
+	return nil
+}
+
+// queryPowerScheme runs powercfg /query (or /qh, which includes hidden settings) for a
+// scheme GUID, alias or stock scheme name (empty for the active scheme) and parses
+// the output
+func queryPowerScheme(scheme string, hidden bool) (*PowerScheme, error) {
+	if guid := builtinSchemeGUID(scheme); guid != "" {
+		scheme = guid
+	}
+	args := []string{"/query"}
+	if hidden {
+		args = []string{"/qh"}
+	}
+	if scheme != "" {
+		args = append(args, scheme)
+	}
+	output, err := runCommandWithEncoding("powercfg", args...)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Energieschemas: %w", err)
+	}
+	parsed := parsePowerSchemeQuery(output)
+	if parsed == nil {
+		return nil, fmt.Errorf("Energieschema %q konnte nicht gelesen werden", scheme)
+	}
+	return parsed, nil
+}
+
+// parsePowerSchemeQuery parses complete "powercfg /query" output. The structure is
+// detected from GUIDs and indentation rather than from the (localized) labels:
+//
+//	Power Scheme GUID: <guid>  (<name>)          <- scheme (no indentation)
+//	  GUID Alias: SCHEME_BALANCED                <- alias of the preceding entry
+//	  Subgroup GUID: <guid>  (<name>)            <- subgroup
+//	    Power Setting GUID: <guid>  (<name>)     <- setting
+//	      Minimum/Maximum/Increment: 0x...       <- range (indented deeper than the setting)
+//	      Units: Seconds
+//	      Possible Setting Index: 000            <- option index, next line is its name
+//	      Possible Setting Friendly Name: Off
+//	    Current AC Power Setting Index: 0x...    <- current values (AC first, then DC)
+//	    Current DC Power Setting Index: 0x...
+//
+// It returns nil if the output contains no scheme.
+func parsePowerSchemeQuery(output string) *PowerScheme {
+	var scheme *PowerScheme
+	var subgroup *PowerSubgroup
+	var setting *PowerSetting
+	var alias *string // alias field of the entry declared on the previous line
+	settingIndent := 0
+	rangeValues := 0
+	currentValues := 0
+	pendingOption := false
+
+	for _, rawLine := range strings.Split(output, "\n") {
+		line := strings.TrimRight(rawLine, "\r ")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		// Entry declarations: scheme, subgroup or setting
+		if m := guidPattern.FindStringSubmatch(trimmed); m != nil && strings.Contains(trimmed, ":") && !pendingOption {
+			guid := strings.ToLower(m[1])
+			name := decodeCP1252(strings.TrimSpace(m[2]))
+			switch {
+			case scheme == nil || indent == 0:
+				if scheme != nil {
+					// Only the first scheme is parsed
+					return scheme
+				}
+				scheme = &PowerScheme{GUID: guid, Name: name}
+				alias = &scheme.Alias
+				subgroup, setting = nil, nil
+			case subgroup == nil || indent <= 2:
+				scheme.Subgroups = append(scheme.Subgroups, PowerSubgroup{GUID: guid, Name: name})
+				subgroup = &scheme.Subgroups[len(scheme.Subgroups)-1]
+				alias = &subgroup.Alias
+				setting = nil
+			default:
+				subgroup.Settings = append(subgroup.Settings, PowerSetting{GUID: guid, Name: name})
+				setting = &subgroup.Settings[len(subgroup.Settings)-1]
+				alias = &setting.Alias
+				settingIndent = indent
+				rangeValues, currentValues = 0, 0
+			}
+			continue
+		}
+
+		idx := strings.Index(trimmed, ":")
+		if idx < 0 {
+			continue
+		}
+		value := strings.TrimSpace(trimmed[idx+1:])
+
+		// Alias line directly after the declaration
+		if alias != nil {
+			target := alias
+			alias = nil
+			if aliasPattern.MatchString(value) {
+				*target = value
+				continue
+			}
+		}
+		if setting == nil {
+			continue
+		}
+
+		// Name of the possible value declared on the previous line
+		if pendingOption {
+			setting.Options[len(setting.Options)-1].Name = decodeCP1252(value)
+			pendingOption = false
+			continue
+		}
+
+		if m := hexValuePattern.FindStringSubmatch(value); m != nil {
+			number, err := strconv.ParseUint(m[1], 16, 32)
+			if err != nil {
+				continue
+			}
+			if indent > settingIndent {
+				// Range of possible values: minimum, maximum, increment
+				switch rangeValues {
+				case 0:
+					setting.Min = uint32(number)
+					setting.HasRange = true
+				case 1:
+					setting.Max = uint32(number)
+				case 2:
+					setting.Increment = uint32(number)
+				}
+				rangeValues++
+			} else {
+				// Current values: AC first, then DC
+				switch currentValues {
+				case 0:
+					setting.AC, setting.HasAC = uint32(number), true
+				case 1:
+					setting.DC, setting.HasDC = uint32(number), true
+				}
+				currentValues++
+			}
+			continue
+		}
+
+		if optionPattern.MatchString(value) {
+			number, err := strconv.ParseUint(value, 10, 32)
+			if err == nil {
+				setting.Options = append(setting.Options, PowerSettingOption{Index: uint32(number)})
+				pendingOption = true
+			}
+			continue
+		}
+
+		// Remaining text value inside a range setting: the units
+		if setting.HasRange && setting.Units == "" {
+			setting.Units = decodeCP1252(value)
+		}
+	}
+
+	return scheme
+}
+
+// Find returns the setting with the given subgroup and setting (GUID or alias,
+// case-insensitive). An empty subgroup matches every subgroup.
+func (s *PowerScheme) Find(subgroup, setting string) (*PowerSubgroup, *PowerSetting) {
+	for i := range s.Subgroups {
+		sub := &s.Subgroups[i]
+		if subgroup != "" && !matchesGUIDOrAlias(subgroup, sub.GUID, sub.Alias) {
+			continue
+		}
+		for j := range sub.Settings {
+			if matchesGUIDOrAlias(setting, sub.Settings[j].GUID, sub.Settings[j].Alias) {
+				return sub, &sub.Settings[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// matchesGUIDOrAlias compares a user supplied identifier with a GUID and alias
+func matchesGUIDOrAlias(id, guid, alias string) bool {
+	id = strings.TrimSpace(id)
+	return strings.EqualFold(id, guid) || (alias != "" && strings.EqualFold(id, alias))
+}
+
+// FormatValue returns a readable form of a setting index: the option name for
+// enumerations, a duration for settings in seconds, or the number with its units
+func (p *PowerSetting) FormatValue(value uint32) string {
+	for _, option := range p.Options {
+		if option.Index == value {
+			return option.Name
+		}
+	}
+	units := strings.ToLower(p.Units)
+	if strings.Contains(units, "sec") || strings.Contains(units, "sek") {
+		return formatTimeout(int(value))
+	}
+	if p.Units != "" {
+		return fmt.Sprintf("%d %s", value, p.Units)
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// ValidValue reports whether value is one of the possible values or inside the range
+func (p *PowerSetting) ValidValue(value uint32) bool {
+	if len(p.Options) > 0 {
+		for _, option := range p.Options {
+			if option.Index == value {
+				return true
+			}
+		}
+		return false
+	}
+	if p.HasRange {
+		if value < p.Min || value > p.Max {
+			return false
+		}
+		return p.Increment <= 1 || (value-p.Min)%p.Increment == 0
+	}
+	return true
+}
+
+var (
+	dumpHiddenFlag bool
+	dumpOutputPath string
+)
+
+func setupDumpCommand(fs *flag.FlagSet) {
+	fs.BoolVar(&dumpHiddenFlag, "hidden", false, "Include settings hidden in the Control Panel")
+	fs.StringVar(&dumpOutputPath, "o", "", "Save the scheme as JSON snapshot (for diff on another machine)")
+}
+
+// runDumpCommand prints every subgroup and setting of a power scheme
+func runDumpCommand(args []string) int {
+	scheme := ""
+	if len(args) > 0 {
+		scheme = args[0]
+	}
+	parsed, err := queryPowerScheme(scheme, dumpHiddenFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if dumpOutputPath != "" {
+		if err := savePowerScheme(parsed, dumpOutputPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving snapshot: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Snapshot written to %s\n", dumpOutputPath)
+		return 0
+	}
+	showPowerScheme(parsed)
+	return 0
+}
+
+// showPowerScheme prints all settings of a parsed scheme with their possible values
+// and current AC/DC values
+func showPowerScheme(scheme *PowerScheme) {
+	printUTF8ln("=== Energieschema: %s (%s) ===", scheme.Name, scheme.GUID)
+	for _, sub := range scheme.Subgroups {
+		printUTF8ln("\n[%s] %s", sub.Name, formatGUIDAlias(sub.GUID, sub.Alias))
+		for _, setting := range sub.Settings {
+			printUTF8ln("  %s %s", setting.Name, formatGUIDAlias(setting.GUID, setting.Alias))
+			if len(setting.Options) > 0 {
+				var names []string
+				for _, option := range setting.Options {
+					names = append(names, fmt.Sprintf("%d=%s", option.Index, option.Name))
+				}
+				printUTF8ln("    Mögliche Werte: %s", strings.Join(names, ", "))
+			} else if setting.HasRange {
+				printUTF8ln("    Bereich: %d - %d (Schritt %d) %s", setting.Min, setting.Max, setting.Increment, setting.Units)
+			}
+			printUTF8ln("    Netzbetrieb: %s | Batterie: %s",
+				formatSettingIndex(&setting, setting.AC, setting.HasAC), formatSettingIndex(&setting, setting.DC, setting.HasDC))
+		}
+	}
+}
+
+// formatGUIDAlias formats a GUID with its alias for display
+func formatGUIDAlias(guid, alias string) string {
+	if alias != "" {
+		return fmt.Sprintf("(%s, %s)", alias, guid)
+	}
+	return fmt.Sprintf("(%s)", guid)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParsePowerSchemeList(t *testing.T) {
+	// powercfg /list on a French Windows: neither "Balanced" nor "Ausbalanciert" appears
+	output := "\r\nGUID du mode de gestion de l'alimentation : 381b4222-f694-41f0-9685-ff5bb260df2e  (Utilisation normale)\r\n" +
+		"GUID du mode de gestion de l'alimentation : 8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c  (Performances élevées)\r\n" +
+		"GUID du mode de gestion de l'alimentation : a1841308-3541-4fab-bc81-f71556f20b4a  (Économies d'énergie)\r\n" +
+		"GUID du mode de gestion de l'alimentation : 0e3c9a1f-2d44-4c52-9a7e-5b1f3c2d4e6a  (SleepRight) *\r\n"
+
+	schemes := parsePowerSchemeList(output)
+	want := []PowerSchemeEntry{
+		{GUID: balancedSchemeGUID, Name: "Utilisation normale", Builtin: "balanced"},
+		{GUID: highPerformanceSchemeGUID, Name: "Performances élevées", Builtin: "high-performance"},
+		{GUID: powerSaverSchemeGUID, Name: "Économies d'énergie", Builtin: "power-saver"},
+		{GUID: "0e3c9a1f-2d44-4c52-9a7e-5b1f3c2d4e6a", Name: "SleepRight", Active: true},
+	}
+	if len(schemes) != len(want) {
+		t.Fatalf("got %d schemes, want %d: %+v", len(schemes), len(want), schemes)
+	}
+	for i := range want {
+		if schemes[i] != want[i] {
+			t.Errorf("scheme %d = %+v, want %+v", i, schemes[i], want[i])
+		}
+	}
+
+	if got := activeSchemeGUID(schemes); got != "0e3c9a1f-2d44-4c52-9a7e-5b1f3c2d4e6a" {
+		t.Errorf("activeSchemeGUID = %q, want the SleepRight scheme", got)
+	}
+	if got := activeSchemeGUID(schemes[:3]); got != "" {
+		t.Errorf("activeSchemeGUID without active scheme = %q", got)
+	}
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"balanced", balancedSchemeGUID},
+		{"High-Performance", highPerformanceSchemeGUID},
+		{"381B4222-F694-41F0-9685-FF5BB260DF2E", balancedSchemeGUID},
+		{"sleepright", "0e3c9a1f-2d44-4c52-9a7e-5b1f3c2d4e6a"},
+		{"ultimate", ""}, // not installed on this machine
+		{"Ausbalanciert", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if entry := findSchemeEntry(schemes, tt.spec); entry != nil {
+			got = entry.GUID
+		}
+		if got != tt.want {
+			t.Errorf("findSchemeEntry(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParsePowerSchemeQuery(t *testing.T) {
+	standbyIdle := func(units string) PowerSetting {
+		return PowerSetting{
+			GUID: "29f6c1db-86da-48c5-9fdb-f2b67b1f44da", Alias: "STANDBYIDLE",
+			HasRange: true, Min: 0, Max: 0xffffffff, Increment: 1, Units: units,
+			AC: 1800, DC: 900, HasAC: true, HasDC: true,
+		}
+	}
+	rtcWake := func(names ...string) PowerSetting {
+		setting := PowerSetting{
+			GUID: "bd3b718a-0680-4d9d-8ab2-e1d2b4ac806d", Alias: "RTCWAKE",
+			AC: 2, DC: 0, HasAC: true, HasDC: true,
+		}
+		for i, name := range names {
+			setting.Options = append(setting.Options, PowerSettingOption{Index: uint32(i), Name: name})
+		}
+		return setting
+	}
+
+	tests := []struct {
+		fixture    string
+		schemeName string
+		subgroups  []string // names in order
+		settings   map[[2]string]PowerSetting
+	}{
+		{
+			fixture:    "powercfg-query-en.txt",
+			schemeName: "Balanced",
+			subgroups:  []string{"Sleep", "USB settings"},
+			settings: map[[2]string]PowerSetting{
+				{"SUB_SLEEP", "STANDBYIDLE"}:                        standbyIdle("Seconds"),
+				{"238c9fa8-0aad-41ed-83f4-97be242c8f20", "RTCWAKE"}: rtcWake("Disable", "Enable", "Important Wake Timers Only"),
+				// Subgroup and setting without alias
+				{"2a737441-1930-4402-8d77-b2bebba308a3", "0853a681-27c8-4100-a2fd-82013e970683"}: {
+					GUID:     "0853a681-27c8-4100-a2fd-82013e970683",
+					HasRange: true, Max: 100000, Increment: 1, Units: "Millisecond",
+					AC: 50, DC: 50, HasAC: true, HasDC: true,
+				},
+				{"", "usbselectivesuspend"}: {
+					GUID: "48e6b7a6-50f5-4782-a5d4-53bb8f07e226", Alias: "USBSELECTIVESUSPEND",
+					Options: []PowerSettingOption{{0, "Disabled"}, {1, "Enabled"}},
+					AC:      1, DC: 1, HasAC: true, HasDC: true,
+				},
+			},
+		},
+		{
+			// CP1252 encoded like the output of a German Windows
+			fixture:    "powercfg-query-de.txt",
+			schemeName: "Ausbalanciert",
+			subgroups:  []string{"Energie sparen"},
+			settings: map[[2]string]PowerSetting{
+				{"SUB_SLEEP", "STANDBYIDLE"}: standbyIdle("Sekunden"),
+				{"SUB_SLEEP", "UNATTENDSLEEP"}: {
+					GUID: "7bc4a2f9-d8fc-4469-b07b-33eb785aaca0", Alias: "UNATTENDSLEEP",
+					HasRange: true, Max: 0xffffffff, Increment: 1, Units: "Sekunden",
+					AC: 120, DC: 120, HasAC: true, HasDC: true,
+				},
+				{"SUB_SLEEP", "RTCWAKE"}: rtcWake("Deaktivieren", "Aktivieren", "Nur wichtige Zeitgeber zur Aktivierung"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			scheme := parsePowerSchemeQuery(readFixture(t, tt.fixture))
+			if scheme == nil {
+				t.Fatal("no scheme parsed")
+			}
+			if scheme.GUID != balancedSchemeGUID || scheme.Alias != "SCHEME_BALANCED" || scheme.Name != tt.schemeName {
+				t.Errorf("scheme = %s %s %q", scheme.GUID, scheme.Alias, scheme.Name)
+			}
+			var subgroups []string
+			for _, subgroup := range scheme.Subgroups {
+				subgroups = append(subgroups, subgroup.Name)
+			}
+			if !reflect.DeepEqual(subgroups, tt.subgroups) {
+				t.Errorf("subgroups = %q, want %q", subgroups, tt.subgroups)
+			}
+			for key, want := range tt.settings {
+				_, setting := scheme.Find(key[0], key[1])
+				if setting == nil {
+					t.Errorf("Find(%q, %q) found nothing", key[0], key[1])
+					continue
+				}
+				// The names are checked separately, the table only lists the values
+				name := setting.Name
+				got := *setting
+				got.Name = ""
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Find(%q, %q) = %+v, want %+v", key[0], key[1], got, want)
+				}
+				if name == "" || !utf8.ValidString(name) {
+					t.Errorf("setting %s has name %q", want.GUID, name)
+				}
+			}
+		})
+	}
+
+	// German setting names are decoded from CP1252
+	scheme := parsePowerSchemeQuery(readFixture(t, "powercfg-query-de.txt"))
+	if _, setting := scheme.Find("SUB_SLEEP", "UNATTENDSLEEP"); setting == nil || setting.Name != "Zeitlimit für unbeaufsichtigten Leerlauf des Systems" {
+		t.Errorf("UNATTENDSLEEP = %+v", setting)
+	}
+	if parsePowerSchemeQuery("Invalid parameters -- try \"/?\" for help\r\n") != nil {
+		t.Error("error output parsed as scheme")
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Profile is the desired configuration applied by -configure. It is read from a text
+// file with one "key: value" per line; catalog settings can be set for AC and DC
+// separately with "key.ac: value" and "key.dc: value". Lines starting with # are
+// comments.
+type Profile struct {
+	Settings []ProfileSetting
+	Options  map[string]string // profile keys that are not catalog settings
+}
+
+// profileOptions lists the profile keys that are not catalog settings with their
+// valid values
+var profileOptions = map[string][]string{
+	"hibernation":    {"on", "off", "full", "reduced"},
+	"fast-startup":   {"on", "off"},
+	"modern-standby": {"off", "no-network"},
+}
+
+// ProfileSetting is a catalog setting with its desired AC and/or DC value
+type ProfileSetting struct {
+	Alias *SettingAlias
+	AC    uint32
+	DC    uint32
+	HasAC bool
+	HasDC bool
+}
+
+// defaultProfile returns the profile used when -configure is called without -profile.
+// On laptops, important wake timers (e.g. for updates) stay allowed on battery.
+func defaultProfile(hasBattery bool) string {
+	profile := `# SleepRight default profile
+sleep: 30m
+wake-timers: disabled
+`
+	if hasBattery {
+		profile += "wake-timers.dc: important\n"
+	}
+	return profile
+}
+
+// loadProfile reads a profile file or, for an empty path, the built-in default
+func loadProfile(path string) (*Profile, error) {
+	if path == "" {
+		return parseProfile(defaultProfile(systemHasBattery()))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", path, err)
+	}
+	profile, err := parseProfile(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// parseProfile parses the profile text. Settings keep the order of their first
+// appearance; a later line for the same setting overrides earlier values.
+func parseProfile(text string) (*Profile, error) {
+	profile := &Profile{Options: make(map[string]string)}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNumber)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "maintenance-window" {
+			window, err := maintenanceWindowOption(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			profile.Options[key] = window
+			continue
+		}
+		if key == "wake-devices" {
+			devices, err := wakeDevicesOption(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			profile.Options[key] = devices
+			continue
+		}
+		if values, ok := profileOptions[key]; ok {
+			value = strings.ToLower(value)
+			if !slices.Contains(values, value) {
+				return nil, fmt.Errorf("line %d: invalid value %q for %s (use %s)", lineNumber, value, key, strings.Join(values, ", "))
+			}
+			profile.Options[key] = value
+			continue
+		}
+
+		name, mode := key, ""
+		if base, suffix, ok := strings.Cut(key, "."); ok {
+			name, mode = base, suffix
+		}
+		alias := findSettingAlias(name)
+		if alias == nil {
+			return nil, fmt.Errorf("line %d: unknown setting %q", lineNumber, name)
+		}
+		if mode != "" && mode != "ac" && mode != "dc" {
+			return nil, fmt.Errorf("line %d: unknown power source %q (use .ac or .dc)", lineNumber, mode)
+		}
+		index, err := alias.ParseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		setting := profile.setting(alias)
+		if mode != "dc" {
+			setting.AC, setting.HasAC = index, true
+		}
+		if mode != "ac" {
+			setting.DC, setting.HasDC = index, true
+		}
+	}
+	return profile, scanner.Err()
+}
+
+// setting returns the profile entry for a catalog setting, adding it if necessary
+func (p *Profile) setting(alias *SettingAlias) *ProfileSetting {
+	for i := range p.Settings {
+		if p.Settings[i].Alias == alias {
+			return &p.Settings[i]
+		}
+	}
+	p.Settings = append(p.Settings, ProfileSetting{Alias: alias})
+	return &p.Settings[len(p.Settings)-1]
+}
+
+// lookup returns the profile entry for a catalog setting or nil
+func (p *Profile) lookup(alias *SettingAlias) *ProfileSetting {
+	for i := range p.Settings {
+		if p.Settings[i].Alias == alias {
+			return &p.Settings[i]
+		}
+	}
+	return nil
+}
+
+// wakeDevicesOption normalizes a "wake-devices" value: a comma separated list of
+// keyboard and network, or none
+func wakeDevicesOption(value string) (string, error) {
+	var set wakeDeviceSet
+	none := false
+	for _, name := range strings.Split(strings.ToLower(value), ",") {
+		switch strings.TrimSpace(name) {
+		case "keyboard":
+			set.Keyboard = true
+		case "network":
+			set.Network = true
+		case "none":
+			none = true
+		default:
+			return "", fmt.Errorf("invalid wake device %q (use keyboard, network or none)", strings.TrimSpace(name))
+		}
+	}
+	if none && (set.Keyboard || set.Network) {
+		return "", fmt.Errorf("wake devices \"none\" cannot be combined with others")
+	}
+	var names []string
+	if set.Keyboard {
+		names = append(names, "keyboard")
+	}
+	if set.Network {
+		names = append(names, "network")
+	}
+	if len(names) == 0 {
+		return "none", nil
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// WakeDevices returns the devices allowed to wake the computer; without
+// "wake-devices" these are the keyboard and the network adapters
+func (p *Profile) WakeDevices() wakeDeviceSet {
+	value, ok := p.Options["wake-devices"]
+	if !ok {
+		return wakeDeviceSet{Keyboard: true, Network: true}
+	}
+	return wakeDeviceSet{
+		Keyboard: strings.Contains(value, "keyboard"),
+		Network:  strings.Contains(value, "network"),
+	}
+}
+
+// SetMinutes sets a timeout setting for AC and DC (used for -wait)
+func (p *Profile) SetMinutes(name string, minutes int) {
+	setting := p.setting(findSettingAlias(name))
+	seconds := uint32(minutes * 60)
+	setting.AC, setting.DC, setting.HasAC, setting.HasDC = seconds, seconds, true, true
+}
+
+// HibernateTimeoutSet reports whether the profile sets a hibernate timeout other than never
+func (p *Profile) HibernateTimeoutSet() bool {
+	for _, setting := range p.Settings {
+		if setting.Alias.Name == "hibernate" && ((setting.HasAC && setting.AC > 0) || (setting.HasDC && setting.DC > 0)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the profile for contradicting entries
+func (p *Profile) Validate() error {
+	switch p.Options["hibernation"] {
+	case "off", "reduced":
+		if p.HibernateTimeoutSet() {
+			return fmt.Errorf("a hibernate timeout requires \"hibernation: on\" or \"hibernation: full\", not %q", p.Options["hibernation"])
+		}
+	}
+	return nil
+}
+
+// configureProfileSettings writes the catalog settings of the profile to the active
+// scheme and re-applies it. Values that are already set are skipped; every AC and DC
+// value is a step of its own.
+func configureProfileSettings(profile *Profile) []StepResult {
+	if len(profile.Settings) == 0 {
+		return nil
+	}
+	fmt.Println("Configuring power settings from profile...")
+
+	var results stepResults
+	current, err := queryPowerScheme("", true)
+	if err != nil && verboseFlag {
+		fmt.Printf("  Warning: Could not read the current values: %v\n", err)
+	}
+	changed := false
+	for _, setting := range profile.Settings {
+		alias := setting.Alias
+		var existing *PowerSetting
+		if current != nil {
+			_, existing = current.Find(alias.Subgroup, alias.Setting)
+		}
+		for _, mode := range []string{"ac", "dc"} {
+			value, wanted, has := setting.AC, setting.HasAC, existing != nil && existing.HasAC
+			if mode == "dc" {
+				value, wanted, has = setting.DC, setting.HasDC, existing != nil && existing.HasDC
+			}
+			if !wanted {
+				continue
+			}
+			target := alias.Name + "." + mode
+			if current != nil && existing == nil {
+				results.add("setting", target, fmt.Errorf("setting does not exist on this system"))
+				continue
+			}
+			if has && ((mode == "ac" && existing.AC == value) || (mode == "dc" && existing.DC == value)) {
+				results.add("setting", target, skipped("already %s", alias.FormatValue(value)))
+				continue
+			}
+			if results.add("setting", target, writeSettingIndex(mode, alias.Subgroup, alias.Setting, value)) == stepApplied {
+				changed = true
+			}
+		}
+		fmt.Printf("  %s: %s\n", alias.Name, formatProfileSetting(setting))
+	}
+
+	// Apply the changes
+	if changed {
+		results.add("apply", "SCHEME_CURRENT", applyActiveScheme())
+	}
+	return results
+}
+
+// formatProfileSetting formats the desired values of a profile setting
+func formatProfileSetting(setting ProfileSetting) string {
+	switch {
+	case setting.HasAC && setting.HasDC && setting.AC == setting.DC:
+		return setting.Alias.ProfileValue(setting.AC)
+	case setting.HasAC && setting.HasDC:
+		return fmt.Sprintf("AC %s, DC %s", setting.Alias.ProfileValue(setting.AC), setting.Alias.ProfileValue(setting.DC))
+	case setting.HasAC:
+		return "AC " + setting.Alias.ProfileValue(setting.AC)
+	default:
+		return "DC " + setting.Alias.ProfileValue(setting.DC)
+	}
+}
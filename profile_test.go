@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestProfileWakeDevices(t *testing.T) {
+	tests := []struct {
+		line    string
+		option  string
+		want    wakeDeviceSet
+		wantErr bool
+	}{
+		{"", "", wakeDeviceSet{Keyboard: true, Network: true}, false},
+		{"wake-devices: keyboard, network", "keyboard, network", wakeDeviceSet{Keyboard: true, Network: true}, false},
+		{"wake-devices: Network,Keyboard", "keyboard, network", wakeDeviceSet{Keyboard: true, Network: true}, false},
+		{"wake-devices: keyboard", "keyboard", wakeDeviceSet{Keyboard: true}, false},
+		{"wake-devices: network", "network", wakeDeviceSet{Network: true}, false},
+		{"wake-devices: none", "none", wakeDeviceSet{}, false},
+		{"wake-devices: none, keyboard", "", wakeDeviceSet{}, true},
+		{"wake-devices: mouse", "", wakeDeviceSet{}, true},
+		{"wake-devices:", "", wakeDeviceSet{}, true},
+	}
+	for _, tt := range tests {
+		profile, err := parseProfile(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %t", tt.line, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if option := profile.Options["wake-devices"]; option != tt.option {
+			t.Errorf("%q: option %q, want %q", tt.line, option, tt.option)
+		}
+		if got := profile.WakeDevices(); got != tt.want {
+			t.Errorf("%q: WakeDevices = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+// registryStore reads and writes DWORD values below HKEY_LOCAL_MACHINE. The logic
+// using it can be exercised with an in-memory implementation.
+type registryStore interface {
+	// GetDWORD returns the value and whether it exists
+	GetDWORD(path, name string) (uint32, bool, error)
+	SetDWORD(path, name string, value uint32) error
+	// DeleteValue removes a value; a missing value is not an error
+	DeleteValue(path, name string) error
+}
+
+// systemRegistry is the registry used outside of tests
+var systemRegistry registryStore = auditedRegistry{windowsRegistry{}}
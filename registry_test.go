@@ -0,0 +1,44 @@
+package main
+
+import "errors"
+
+// memoryRegistry is an in-memory registryStore for tests. Values are keyed by
+// path and name; getErr and setErr make every read or write fail.
+type memoryRegistry struct {
+	values map[string]uint32
+	getErr error
+	setErr error
+	writes int // successful SetDWORD and DeleteValue calls
+}
+
+func newMemoryRegistry() *memoryRegistry {
+	return &memoryRegistry{values: make(map[string]uint32)}
+}
+
+func (r *memoryRegistry) GetDWORD(path, name string) (uint32, bool, error) {
+	if r.getErr != nil {
+		return 0, false, r.getErr
+	}
+	value, found := r.values[path+`\`+name]
+	return value, found, nil
+}
+
+func (r *memoryRegistry) SetDWORD(path, name string, value uint32) error {
+	if r.setErr != nil {
+		return r.setErr
+	}
+	r.values[path+`\`+name] = value
+	r.writes++
+	return nil
+}
+
+func (r *memoryRegistry) DeleteValue(path, name string) error {
+	if r.setErr != nil {
+		return r.setErr
+	}
+	delete(r.values, path+`\`+name)
+	r.writes++
+	return nil
+}
+
+var errAccessDenied = errors.New("access is denied")
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// windowsRegistry is the registryStore backed by the Windows registry
+type windowsRegistry struct{}
+
+func (windowsRegistry) GetDWORD(path, name string) (uint32, bool, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("Fehler beim Öffnen von HKLM\\%s: %w", path, err)
+	}
+	defer key.Close()
+
+	value, _, err := key.GetIntegerValue(name)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("Fehler beim Lesen von HKLM\\%s\\%s: %w", path, name, err)
+	}
+	return uint32(value), true, nil
+}
+
+func (windowsRegistry) SetDWORD(path, name string, value uint32) error {
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, path, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open HKLM\\%s: %w", path, err)
+	}
+	defer key.Close()
+
+	if err := key.SetDWordValue(name, value); err != nil {
+		return fmt.Errorf("failed to write HKLM\\%s\\%s: %w", path, name, err)
+	}
+	return nil
+}
+
+func (windowsRegistry) DeleteValue(path, name string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.SET_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to open HKLM\\%s: %w", path, err)
+	}
+	defer key.Close()
+
+	if err := key.DeleteValue(name); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to delete HKLM\\%s\\%s: %w", path, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportHTMLTemplate string
+
+// reportTimelineDays and reportTimelineWidth size the timeline embedded in reports
+const (
+	reportTimelineDays  = 14
+	reportTimelineWidth = 160
+)
+
+// Report is the data collected for a SleepRight report. It is filled by
+// collectReport and rendered by the report renderers without further system access.
+type Report struct {
+	Generated     time.Time
+	Hostname      string
+	Version       string
+	SchemeGUID    string
+	SchemeName    string
+	Timeouts      []TimeoutSetting
+	WakeDevices   []WakeDevice
+	ArmedCount    int
+	SleepStates   string
+	WakeTimers    string
+	PowerRequests string
+	LastWake      string
+	Events        []WakeEvent
+	Findings      []Finding
+	Timeline      []string
+	Errors        []string // data that could not be collected
+}
+
+// TimeoutSetting is a timeout of the active power scheme in seconds (0 = disabled)
+type TimeoutSetting struct {
+	Name         string
+	AC, DC       int
+	HasAC, HasDC bool
+}
+
+// reportTimeouts lists the timeouts shown in reports
+var reportTimeouts = []struct {
+	name, subgroup, setting string
+}{
+	{"Bildschirm ausschalten nach", "SUB_VIDEO", "VIDEOIDLE"},
+	{"Energiesparmodus nach", "SUB_SLEEP", "STANDBYIDLE"},
+	{"Ruhezustand nach", "SUB_SLEEP", "HIBERNATEIDLE"},
+}
+
+var (
+	reportOutputPath string
+	reportFullFlag   bool
+)
+
+func setupReportCommand(fs *flag.FlagSet) {
+	fs.StringVar(&reportOutputPath, "o", "sleepright-report.html", "Output file")
+	fs.BoolVar(&reportFullFlag, "full", false, "Include all wake events, not only the last 24 hours")
+}
+
+func runReportCommand(args []string) int {
+	report := collectReport(reportFullFlag, unicodeTimelineGlyphs)
+
+	file, err := os.Create(reportOutputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating report: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	if err := renderHTMLReport(file, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Report written to %s\n", reportOutputPath)
+	return 0
+}
+
+// collectReport gathers all data shown in a report. Failures of individual queries
+// are recorded in Report.Errors so that the rest of the report is still useful.
+// The glyphs are used for the timeline, which is rendered as text.
+func collectReport(full bool, glyphs timelineGlyphs) *Report {
+	report := &Report{Generated: time.Now(), Version: Version}
+	report.Hostname, _ = os.Hostname()
+
+	addError := func(what string, err error) {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	if output, err := runCommandWithEncoding("powercfg", "/getactivescheme"); err != nil {
+		addError("Aktives Energieschema", err)
+	} else {
+		report.SchemeGUID, report.SchemeName = parseActiveScheme(output)
+	}
+
+	for _, timeout := range reportTimeouts {
+		output, err := runCommandWithEncoding("powercfg", "/query", "SCHEME_CURRENT", timeout.subgroup, timeout.setting)
+		if err != nil {
+			addError(timeout.name, err)
+			continue
+		}
+		setting := TimeoutSetting{Name: timeout.name}
+		setting.AC, setting.HasAC = parsePowerSettingValue(output, "AC Setting Index")
+		setting.DC, setting.HasDC = parsePowerSettingValue(output, "DC Setting Index")
+		report.Timeouts = append(report.Timeouts, setting)
+	}
+
+	if devices, armedCount, err := collectWakeDevices(); err != nil {
+		addError("Aufweck-Geräte", err)
+	} else {
+		for i := range devices {
+			devices[i].Name = decodeCP1252(devices[i].Name)
+		}
+		report.WakeDevices, report.ArmedCount = devices, armedCount
+	}
+
+	commandOutputs := []struct {
+		target *string
+		name   string
+		args   []string
+	}{
+		{&report.SleepStates, "Standby-Zustände", []string{"/a"}},
+		{&report.WakeTimers, "Aufweck-Zeitgeber", []string{"/waketimers"}},
+		{&report.PowerRequests, "Energieanfragen", []string{"/requests"}},
+		{&report.LastWake, "Letztes Aufweck-Ereignis", []string{"/lastwake"}},
+	}
+	for _, c := range commandOutputs {
+		output, err := runCommandWithEncoding("powercfg", c.args...)
+		if err != nil {
+			addError(c.name, err)
+			continue
+		}
+		*c.target = decodeCP1252(strings.TrimSpace(output))
+	}
+
+	events, err := queryEventLogWakeEvents(wakeEventQueryCount)
+	if err != nil {
+		addError("Ereignisprotokoll", err)
+	}
+	for i := range events {
+		events[i].Source = decodeCP1252(events[i].Source)
+	}
+	baseline, err := loadWakeBaseline()
+	if err != nil && verboseFlag {
+		printUTF8ln("Hinweis: Konnte Aufweck-Basislinie nicht laden: %v", err)
+	}
+	report.Findings = append(detectWakeAnomalies(events, baseline), querySystemFindings()...)
+	report.Timeline = renderSleepTimeline(events, reportTimelineDays, reportTimelineWidth, report.Generated, glyphs)
+	for _, event := range events {
+		if full || event.Timestamp.After(report.Generated.Add(-24*time.Hour)) {
+			report.Events = append(report.Events, event)
+		}
+	}
+
+	return report
+}
+
+// parseActiveScheme extracts GUID and name from powercfg /getactivescheme output
+func parseActiveScheme(output string) (guid, name string) {
+	re := regexp.MustCompile(`([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\s*\(([^)]*)\)`)
+	matches := re.FindStringSubmatch(output)
+	if len(matches) < 3 {
+		return "", ""
+	}
+	return strings.ToLower(matches[1]), decodeCP1252(strings.TrimSpace(matches[2]))
+}
+
+// reportTemplateFuncs are the helper functions available in report templates
+var reportTemplateFuncs = template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Local().Format("02.01.2006 15:04:05") },
+	"timeout":  formatTimeout,
+	"sleepDuration": func(event WakeEvent) string {
+		return formatDuration(event.Timestamp.Sub(event.SleepTime))
+	},
+	"join": strings.Join,
+}
+
+// renderHTMLReport writes the report as a self-contained HTML page (embedded CSS and
+// JavaScript, no network access)
+func renderHTMLReport(w io.Writer, report *Report) error {
+	tmpl, err := template.New("report").Funcs(reportTemplateFuncs).Parse(reportHTMLTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testReport is a fixed report covering all sections of the HTML and Markdown reports
+func testReport() *Report {
+	cet := time.FixedZone("CET", 3600)
+	generated := time.Date(2025, 12, 19, 9, 30, 0, 0, cet)
+	events := []WakeEvent{
+		{Timestamp: time.Date(2025, 12, 19, 7, 15, 0, 0, cet), SleepTime: time.Date(2025, 12, 18, 23, 5, 0, 0, cet), Source: "HID Keyboard Device"},
+		{Timestamp: time.Date(2025, 12, 19, 3, 0, 12, 0, cet), SleepTime: time.Date(2025, 12, 19, 2, 58, 40, 0, cet), Source: "Zeitgeber - Windows will execute 'NT TASK\\Microsoft\\Windows\\UpdateOrchestrator\\Reboot' <script>"},
+	}
+	return &Report{
+		Generated:  generated,
+		Hostname:   "PC-HELPDESK",
+		Version:    "1.0.4.17",
+		SchemeGUID: "381b4222-f694-41f0-9685-ff5bb260df2e",
+		SchemeName: "Ausbalanciert",
+		Timeouts: []TimeoutSetting{
+			{Name: "Bildschirm ausschalten nach", AC: 600, DC: 300, HasAC: true, HasDC: true},
+			{Name: "Energiesparmodus nach", AC: 1800, DC: 900, HasAC: true, HasDC: true},
+			{Name: "Ruhezustand nach", AC: 0, HasAC: true},
+		},
+		WakeDevices: []WakeDevice{
+			{Name: "HID Keyboard Device", Armed: true},
+			{Name: "Intel(R) Ethernet Connection I219-V", Armed: true, IsNetwork: true, MagicPacketOnly: true},
+			{Name: "HID-konforme Maus", Armed: false},
+		},
+		ArmedCount:    2,
+		SleepStates:   "Die folgenden Standbymodi sind auf diesem System verfügbar:\n    Standby (S3)",
+		WakeTimers:    "Es gibt keine aktiven Zeitgeber zur Aktivierung im System.",
+		PowerRequests: "DISPLAY:\nKeine.",
+		LastWake:      "Aktivierungsverlaufzähler - 1",
+		Events:        events,
+		Findings: []Finding{{
+			Kind:     findingNewSource,
+			Title:    "Neue Aufweckquelle: Zeitgeber",
+			Evidence: []time.Time{events[1].Timestamp},
+			Cause:    "Ein Zeitgeber hat den Computer geweckt",
+		}},
+		Timeline: renderSleepTimeline(events, 2, 80, generated, asciiTimelineGlyphs),
+		Errors:   []string{"Energieanfragen: access denied"},
+	}
+}
+
+func TestRenderHTMLReportGolden(t *testing.T) {
+	// datetime formats in the local time zone
+	local := time.Local
+	time.Local = time.FixedZone("CET", 3600)
+	defer func() { time.Local = local }()
+
+	var out bytes.Buffer
+	if err := renderHTMLReport(&out, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "report.golden.html", out.Bytes())
+}
+
+func TestRenderMarkdownReportGolden(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("CET", 3600)
+	defer func() { time.Local = local }()
+
+	var out bytes.Buffer
+	if err := renderMarkdownReport(&out, testReport(), true); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "report.golden.md", out.Bytes())
+
+	// Without -info-full only armed devices are listed
+	out.Reset()
+	if err := renderMarkdownReport(&out, testReport(), false); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("HID-konforme Maus")) {
+		t.Errorf("device that is not armed listed in the short report:\n%s", out.String())
+	}
+}
+
+// checkGolden compares output with a golden file in testdata; "go test -update"
+// rewrites the file instead
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	// Git may check the file out with CRLF line endings on Windows
+	want = bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n"))
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update and review the diff):\n%s", golden, got)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Name and description of the SleepRight Windows service
+const (
+	serviceName        = "SleepRight"
+	serviceDisplayName = "SleepRight"
+	serviceDescription = "Checks the SleepRight profile periodically and re-applies drifted power settings"
+)
+
+var serviceInterval time.Duration
+
+func setupServiceCommand(fs *flag.FlagSet) {
+	fs.StringVar(&profilePath, "profile", "", "Profile enforced by the service (default: built-in profile)")
+	fs.DurationVar(&serviceInterval, "interval", defaultEnforceInterval, "Time between two checks")
+}
+
+// runServiceCommand installs, removes or runs the enforcement service
+func runServiceCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight service [-profile <file>] [-interval 15m] install|uninstall|run\n")
+		return 1
+	}
+	if serviceInterval < time.Minute {
+		fmt.Fprintf(os.Stderr, "Error: the interval must be at least one minute\n")
+		return 1
+	}
+	var err error
+	switch args[0] {
+	case "install":
+		err = installService()
+	case "uninstall":
+		err = uninstallService()
+	case "run":
+		if err = runService(); err != nil {
+			writeEvent(errorEvent("service", err))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight service [-profile <file>] [-interval 15m] install|uninstall|run\n")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
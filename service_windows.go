@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// sleepRightService is the svc.Handler running the enforcement loop
+type sleepRightService struct {
+	enforcer *enforcer
+}
+
+// Execute runs the enforcer until the service manager stops the service
+func (s *sleepRightService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.enforcer.Run(stop, nil)
+		close(done)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for request := range requests {
+		switch request.Cmd {
+		case svc.Interrogate:
+			status <- request.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending}
+			close(stop)
+			// A running pass is not interrupted; do not block the service manager for it
+			select {
+			case <-done:
+			case <-time.After(20 * time.Second):
+			}
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// openServiceLog opens the service log in the data directory
+func openServiceLog() (*log.Logger, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, "service.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open service log: %w", err)
+	}
+	return log.New(file, "", log.LstdFlags), nil
+}
+
+// runService runs the enforcement loop as Windows service or, when started from a
+// console, in the foreground until Ctrl+C
+func runService() error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return fmt.Errorf("failed to determine the session type: %w", err)
+	}
+
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	if isService {
+		if logger, err = openServiceLog(); err != nil {
+			return err
+		}
+	}
+	e := &enforcer{
+		clock:    systemClock{},
+		runner:   profileRunner{path: profilePath},
+		interval: serviceInterval,
+		logf:     logger.Printf,
+		emit:     writeEvent,
+	}
+
+	if isService {
+		return svc.Run(serviceName, &sleepRightService{enforcer: e})
+	}
+	e.Run(nil, nil)
+	return nil
+}
+
+// installService registers SleepRight as automatically started service that runs
+// "SleepRight service run" with the given profile and interval
+func installService() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to determine executable path: %w", err)
+	}
+	args := []string{"service", "-interval", serviceInterval.String()}
+	if profilePath != "" {
+		path, err := filepath.Abs(profilePath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve profile path: %w", err)
+		}
+		if _, err := loadProfile(path); err != nil {
+			return err
+		}
+		args = append(args, "-profile", path)
+	}
+	args = append(args, "run")
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed (run \"SleepRight service uninstall\" first)", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("service installed, but failed to start: %w", err)
+	}
+	fmt.Printf("Service %s installed and started (%s).\n", serviceName, strings.Join(args, " "))
+	return nil
+}
+
+// uninstallService stops and removes the SleepRight service
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+	if status, err := s.Control(svc.Stop); err == nil {
+		for i := 0; i < 20 && status.State != svc.Stopped; i++ {
+			time.Sleep(500 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to remove service: %w", err)
+	}
+	fmt.Printf("Service %s removed.\n", serviceName)
+	return nil
+}
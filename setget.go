@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resolveSetting finds a setting in the scheme by catalog name, setting GUID or
+// powercfg alias (e.g. STANDBYIDLE). alias is nil if the setting is not in the catalog.
+func resolveSetting(scheme *PowerScheme, name string) (*SettingAlias, *PowerSubgroup, *PowerSetting, error) {
+	if alias := findSettingAlias(name); alias != nil {
+		subgroup, setting := scheme.Find(alias.Subgroup, alias.Setting)
+		if setting == nil {
+			return nil, nil, nil, fmt.Errorf("Einstellung %q ist auf diesem System nicht verfügbar", name)
+		}
+		return alias, subgroup, setting, nil
+	}
+	subgroup, setting := scheme.Find("", name)
+	if setting == nil {
+		return nil, nil, nil, fmt.Errorf("unbekannte Einstellung %q (Katalogname, GUID oder Alias erwartet)", name)
+	}
+	return nil, subgroup, setting, nil
+}
+
+// parseSettingIndex converts a value given on the command line to a setting index.
+// Catalog settings use their value names and durations, other settings accept an
+// option name, a decimal number or a 0x hex number.
+func parseSettingIndex(alias *SettingAlias, setting *PowerSetting, value string) (uint32, error) {
+	if alias != nil {
+		return alias.ParseValue(value)
+	}
+	for _, option := range setting.Options {
+		if strings.EqualFold(option.Name, strings.TrimSpace(value)) {
+			return option.Index, nil
+		}
+	}
+	number, err := strconv.ParseUint(strings.TrimSpace(value), 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s", value, setting.Name)
+	}
+	return uint32(number), nil
+}
+
+// writeSettingIndex sets the AC ("ac") or DC ("dc") value of a setting in the active
+// scheme. The change takes effect after "powercfg /setactive SCHEME_CURRENT".
+func writeSettingIndex(mode, subgroup, setting string, value uint32) error {
+	change := auditChange{Action: "setting", Target: setting + " (" + strings.ToUpper(mode) + ")", New: strconv.FormatUint(uint64(value), 10)}
+	if previous, found := readSettingIndex(mode, subgroup, setting); found {
+		change.Previous = strconv.FormatUint(uint64(previous), 10)
+	}
+	err := runAudited(change, "powercfg", "/set"+mode+"valueindex", "SCHEME_CURRENT", subgroup, setting, strconv.FormatUint(uint64(value), 10))
+	if err != nil {
+		return fmt.Errorf("failed to set %s value: %w", strings.ToUpper(mode), err)
+	}
+	return nil
+}
+
+// readSettingIndex returns the current AC or DC value of a setting in the active
+// scheme, for the audit log
+func readSettingIndex(mode, subgroup, setting string) (uint32, bool) {
+	output, err := runCommandWithEncoding("powercfg", "/qh", "SCHEME_CURRENT", subgroup, setting)
+	if err != nil {
+		return 0, false
+	}
+	scheme := parsePowerSchemeQuery(output)
+	if scheme == nil {
+		return 0, false
+	}
+	_, current := scheme.Find(subgroup, setting)
+	if current == nil {
+		return 0, false
+	}
+	if mode == "ac" {
+		return current.AC, current.HasAC
+	}
+	return current.DC, current.HasDC
+}
+
+// applyActiveScheme activates the current scheme again so changed values take effect
+func applyActiveScheme() error {
+	change := auditChange{Action: "scheme", Target: "SCHEME_CURRENT", New: "active"}
+	if output, err := runCommandWithEncoding("powercfg", "/getactivescheme"); err == nil {
+		change.Previous, _ = parseActiveScheme(output)
+	}
+	if err := runAudited(change, "powercfg", "/setactive", "SCHEME_CURRENT"); err != nil {
+		return fmt.Errorf("failed to apply power settings: %w", err)
+	}
+	return nil
+}
+
+// runGetCommand shows the current values and possible values of a setting
+func runGetCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight get <setting>\n")
+		return 1
+	}
+	scheme, err := queryPowerScheme("", true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	alias, subgroup, setting, err := resolveSetting(scheme, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	printUTF8ln("%s %s", setting.Name, formatGUIDAlias(setting.GUID, setting.Alias))
+	printUTF8ln("  Untergruppe: %s %s", subgroup.Name, formatGUIDAlias(subgroup.GUID, subgroup.Alias))
+	if alias != nil {
+		printUTF8ln("  Name: %s", alias.Name)
+		if len(alias.Values) > 0 {
+			printUTF8ln("  Mögliche Werte: %s", alias.valueNames())
+		}
+	}
+	if len(setting.Options) > 0 {
+		var names []string
+		for _, option := range setting.Options {
+			names = append(names, fmt.Sprintf("%d=%s", option.Index, option.Name))
+		}
+		printUTF8ln("  Indizes: %s", strings.Join(names, ", "))
+	} else if setting.HasRange {
+		printUTF8ln("  Bereich: %d - %d (Schritt %d) %s", setting.Min, setting.Max, setting.Increment, setting.Units)
+	}
+	printUTF8ln("  Netzbetrieb: %s", formatSettingIndex(setting, setting.AC, setting.HasAC))
+	printUTF8ln("  Batterie:    %s", formatSettingIndex(setting, setting.DC, setting.HasDC))
+	return 0
+}
+
+// runSetCommand sets the AC and/or DC value of a setting in the active scheme.
+// Values are given as ac=<v> and dc=<v>; a single value without prefix sets both.
+func runSetCommand(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight set <setting> ac=<value> dc=<value>\n")
+		return 1
+	}
+	scheme, err := queryPowerScheme("", true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	alias, subgroup, setting, err := resolveSetting(scheme, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	// Parse and validate all values before changing anything
+	values := make(map[string]uint32)
+	for _, arg := range args[1:] {
+		mode, value, found := strings.Cut(arg, "=")
+		modes := []string{strings.ToLower(mode)}
+		if !found {
+			value, modes = arg, []string{"ac", "dc"}
+		} else if modes[0] != "ac" && modes[0] != "dc" {
+			fmt.Fprintf(os.Stderr, "Error: unknown power source %q (use ac=<value> or dc=<value>)\n", mode)
+			return 1
+		}
+		index, err := parseSettingIndex(alias, setting, value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if !setting.ValidValue(index) {
+			fmt.Fprintf(os.Stderr, "Error: value %d is not allowed for %s\n", index, setting.Name)
+			return 1
+		}
+		for _, m := range modes {
+			values[m] = index
+		}
+	}
+
+	for _, mode := range []string{"ac", "dc"} {
+		index, ok := values[mode]
+		if !ok {
+			continue
+		}
+		if err := writeSettingIndex(mode, subgroup.GUID, setting.GUID, index); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		printUTF8ln("%s (%s): %s", setting.Name, strings.ToUpper(mode), setting.FormatValue(index))
+	}
+
+	// Apply the changes
+	if err := applyActiveScheme(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
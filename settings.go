@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettingAlias maps a readable name (as used in profiles and the info output) to a
+// power setting and the meaning of its values
+type SettingAlias struct {
+	Name        string
+	Description string // German description for the info output
+	Subgroup    string // subgroup GUID
+	Setting     string // setting GUID
+	Values      []SettingAliasValue
+	Seconds     bool // the value is a timeout in seconds (0 = never)
+}
+
+// SettingAliasValue is a named value of an enumerated setting
+type SettingAliasValue struct {
+	Name  string
+	Index uint32
+}
+
+// Subgroup GUIDs used by the catalog
+const (
+	subgroupNone    = "fea3413e-7e05-4911-9a71-700331f1c294"
+	subgroupSleep   = "238c9fa8-0aad-41ed-83f4-97be242c8f20"
+	subgroupUSB     = "2a737441-1930-4402-8d77-b2bebba308a3"
+	subgroupPCIe    = "501a4d13-42af-4429-9fd1-a8218c268e20"
+	subgroupVideo   = "7516b95f-f776-4464-8c53-06167f40cc99"
+	subgroupButtons = "4f971e89-eebd-4455-a8de-9e59040e7347"
+)
+
+var (
+	offOnValues    = []SettingAliasValue{{"off", 0}, {"on", 1}}
+	disabledValues = []SettingAliasValue{{"disabled", 0}, {"enabled", 1}}
+	buttonValues   = []SettingAliasValue{{"nothing", 0}, {"sleep", 1}, {"hibernate", 2}, {"shutdown", 3}}
+)
+
+// settingCatalog lists the power settings SleepRight knows by name
+var settingCatalog = []SettingAlias{
+	{Name: "sleep", Description: "Energiesparmodus nach", Subgroup: subgroupSleep, Setting: "29f6c1db-86da-48c5-9fdb-f2b67b1f44da", Seconds: true},
+	{Name: "hibernate", Description: "Ruhezustand nach", Subgroup: subgroupSleep, Setting: "9d7815a6-7ee4-497e-8888-515a05f02364", Seconds: true},
+	{Name: "hybrid-sleep", Description: "Hybriden Standbymodus zulassen", Subgroup: subgroupSleep, Setting: "94ac6d29-73ce-41a6-809f-6363ba21b47e", Values: offOnValues},
+	{Name: "wake-timers", Description: "Zeitgeber zur Aktivierung zulassen", Subgroup: subgroupSleep, Setting: "bd3b718a-0680-4d9d-8ab2-e1d2b4ac806d",
+		Values: []SettingAliasValue{{"disabled", 0}, {"enabled", 1}, {"important", 2}}},
+	{Name: "unattended-sleep", Description: "Zeitlimit für unbeaufsichtigten Standby", Subgroup: subgroupSleep, Setting: "7bc4a2f9-d8fc-4469-b07b-33eb785aaca0", Seconds: true},
+	{Name: "away-mode", Description: "Abwesenheitsmodus", Subgroup: subgroupSleep, Setting: "25dfa149-5dd1-4736-b5ab-e8a37b5b8187", Values: offOnValues},
+	{Name: "standby-network", Description: "Netzwerkverbindung im Standby (Modern Standby)", Subgroup: subgroupNone, Setting: networkStandbySetting,
+		Values: []SettingAliasValue{{"disabled", 0}, {"enabled", 1}, {"managed", 2}}},
+	{Name: "usb-selective-suspend", Description: "USB selektives Energiesparen", Subgroup: subgroupUSB, Setting: "48e6b7a6-50f5-4782-a5d4-53bb8f07e226", Values: disabledValues},
+	{Name: "pcie-link-state", Description: "PCI Express Verbindungszustand-Energieverwaltung", Subgroup: subgroupPCIe, Setting: "ee12f906-d277-404b-b6da-e5fa1a576df5",
+		Values: []SettingAliasValue{{"off", 0}, {"moderate", 1}, {"maximum", 2}}},
+	{Name: "display-off", Description: "Bildschirm ausschalten nach", Subgroup: subgroupVideo, Setting: "3c0bc021-c8a8-4e07-a973-6b14cbcb2b7e", Seconds: true},
+	{Name: "lid-action", Description: "Aktion beim Zuklappen", Subgroup: subgroupButtons, Setting: "5ca83367-6e45-459f-a27b-476b1d01c936", Values: buttonValues},
+	{Name: "power-button", Description: "Aktion beim Drücken des Netzschalters", Subgroup: subgroupButtons, Setting: "7648efa3-dd9c-4e3e-b566-50f929386280",
+		Values: append(append([]SettingAliasValue{}, buttonValues...), SettingAliasValue{"display-off", 4})},
+}
+
+// findSettingAlias returns the catalog entry with the given name (case-insensitive) or nil
+func findSettingAlias(name string) *SettingAlias {
+	for i := range settingCatalog {
+		if strings.EqualFold(settingCatalog[i].Name, strings.TrimSpace(name)) {
+			return &settingCatalog[i]
+		}
+	}
+	return nil
+}
+
+// ParseValue converts a profile value to a setting index. Enumerated settings accept
+// the value name or its number; timeouts accept a duration ("30m", "2h"), a number of
+// minutes (like powercfg /change) or "never".
+func (a *SettingAlias) ParseValue(value string) (uint32, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, v := range a.Values {
+		if v.Name == value {
+			return v.Index, nil
+		}
+	}
+
+	if a.Seconds {
+		switch value {
+		case "never", "off", "0":
+			return 0, nil
+		}
+		// powercfg stores timeouts as seconds in a DWORD
+		if minutes, err := strconv.ParseUint(value, 10, 32); err == nil {
+			if minutes > math.MaxUint32/60 {
+				return 0, fmt.Errorf("timeout %q for %s is too long", value, a.Name)
+			}
+			return uint32(minutes * 60), nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid timeout %q for %s (use e.g. 30m, 2h or never)", value, a.Name)
+		}
+		seconds := d / time.Second
+		switch {
+		case seconds > math.MaxUint32:
+			return 0, fmt.Errorf("timeout %q for %s is too long", value, a.Name)
+		case seconds == 0 && d > 0:
+			// Would silently mean "never"
+			return 0, fmt.Errorf("timeout %q for %s is shorter than a second (use never to turn it off)", value, a.Name)
+		}
+		return uint32(seconds), nil
+	}
+
+	number, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s (use %s)", value, a.Name, a.valueNames())
+	}
+	return uint32(number), nil
+}
+
+// FormatValue returns the readable form of a setting index as used in profiles
+func (a *SettingAlias) FormatValue(index uint32) string {
+	for _, v := range a.Values {
+		if v.Index == index {
+			return v.Name
+		}
+	}
+	if a.Seconds {
+		return formatTimeout(int(index))
+	}
+	return strconv.FormatUint(uint64(index), 10)
+}
+
+// ProfileValue returns a setting index in profile syntax ("disabled", "30m", "never")
+func (a *SettingAlias) ProfileValue(index uint32) string {
+	if !a.Seconds {
+		return a.FormatValue(index)
+	}
+	switch {
+	case index == 0:
+		return "never"
+	case index%3600 == 0:
+		return fmt.Sprintf("%dh", index/3600)
+	case index%60 == 0:
+		return fmt.Sprintf("%dm", index/60)
+	default:
+		return fmt.Sprintf("%ds", index)
+	}
+}
+
+// valueNames lists the named values of an enumerated setting for error messages
+func (a *SettingAlias) valueNames() string {
+	var names []string
+	for _, v := range a.Values {
+		names = append(names, v.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// queryCatalogSetting queries a single catalog setting of the active scheme
+func queryCatalogSetting(alias *SettingAlias) (*PowerSetting, error) {
+	output, err := runCommandWithEncoding("powercfg", "/qh", "SCHEME_CURRENT", alias.Subgroup, alias.Setting)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen von %s: %w", alias.Name, err)
+	}
+	scheme := parsePowerSchemeQuery(output)
+	if scheme == nil {
+		return nil, fmt.Errorf("Einstellung %s konnte nicht gelesen werden", alias.Name)
+	}
+	_, setting := scheme.Find(alias.Subgroup, alias.Setting)
+	if setting == nil {
+		return nil, fmt.Errorf("Einstellung %s ist auf diesem System nicht verfügbar", alias.Name)
+	}
+	return setting, nil
+}
+
+// showCatalogSettings prints the current AC/DC values of all catalog settings in the
+// active scheme. Settings missing on this machine are skipped.
+func showCatalogSettings() error {
+	scheme, err := queryPowerScheme("", true)
+	if err != nil {
+		return err
+	}
+
+	printUTF8ln("\nEinstellungen (Netzbetrieb | Batterie):")
+	for i := range settingCatalog {
+		alias := &settingCatalog[i]
+		_, setting := scheme.Find(alias.Subgroup, alias.Setting)
+		if setting == nil {
+			if verboseFlag {
+				printUTF8ln("  %-22s nicht verfügbar", alias.Name)
+			}
+			continue
+		}
+		printUTF8ln("  %-22s %s | %s  (%s)", alias.Name,
+			formatAliasIndex(alias, setting.AC, setting.HasAC), formatAliasIndex(alias, setting.DC, setting.HasDC), alias.Description)
+	}
+	return nil
+}
+
+// formatAliasIndex formats a current value that may be missing
+func formatAliasIndex(alias *SettingAlias, value uint32, found bool) string {
+	if !found {
+		return "-"
+	}
+	return alias.FormatValue(value)
+}
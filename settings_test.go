@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestSettingAliasParseValue(t *testing.T) {
+	tests := []struct {
+		setting string
+		value   string
+		want    uint32
+		wantErr bool
+	}{
+		{"wake-timers", "important", 2, false},
+		{"wake-timers", "1", 1, false},
+		{"wake-timers", "sometimes", 0, true},
+		{"sleep", "never", 0, false},
+		{"sleep", "0", 0, false},
+		{"sleep", "30", 1800, false},
+		{"sleep", "30m", 1800, false},
+		{"sleep", "1h30m", 5400, false},
+		{"sleep", "1.5s", 1, false},
+		{"sleep", "0s", 0, false},
+		{"sleep", "-5m", 0, true},
+		{"sleep", "soon", 0, true},
+		// Timeouts are seconds in a DWORD
+		{"sleep", "71582788", 4294967280, false},
+		{"sleep", "71582789", 0, true},
+		{"sleep", "4294967295", 0, true},
+		{"sleep", "4294967295s", 4294967295, false},
+		{"sleep", "4294967296s", 0, true},
+		{"sleep", "1193047h", 0, true},
+		// Shorter than a second would turn the timeout off
+		{"sleep", "500ms", 0, true},
+		{"sleep", "999ms", 0, true},
+		{"sleep", "1s", 1, false},
+	}
+	for _, tt := range tests {
+		got, err := findSettingAlias(tt.setting).ParseValue(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: %s: error %v, want error %t", tt.setting, tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: %s = %d, want %d", tt.setting, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+)
+
+// stepStatus is the outcome of a configuration step
+type stepStatus int
+
+const (
+	stepApplied stepStatus = iota
+	stepSkipped
+	stepFailed
+	stepRequiresAdmin
+	stepNotFound
+)
+
+// String returns the status as shown in the summary table
+func (s stepStatus) String() string {
+	switch s {
+	case stepSkipped:
+		return "skipped"
+	case stepFailed:
+		return "failed"
+	case stepRequiresAdmin:
+		return "requires admin"
+	case stepNotFound:
+		return "not found"
+	default:
+		return "applied"
+	}
+}
+
+// StepResult is the outcome of one configuration step, e.g. one wake device or one
+// AC/DC value
+type StepResult struct {
+	Step   string
+	Target string
+	Status stepStatus
+	Reason string
+}
+
+// skipError reports that a step did not need to change anything
+type skipError struct {
+	reason string
+}
+
+func (e skipError) Error() string { return e.reason }
+
+// skipped returns the error for a step that was left unchanged
+func skipped(format string, args ...interface{}) error {
+	return skipError{reason: fmt.Sprintf(format, args...)}
+}
+
+// accessDeniedTexts and notFoundTexts classify error messages of powercfg, schtasks
+// and the registry (English and German). They are whole phrases because the error
+// text includes the command output, which may name accounts or devices.
+var (
+	accessDeniedTexts = []string{
+		"access is denied", "zugriff verweigert",
+		"do not have permission", "may not have permission", "nicht über die berechtigung", "keine berechtigung",
+		"requires elevation", "erfordert erhöhte rechte",
+	}
+	notFoundTexts = []string{"not found", "does not exist", "nicht gefunden", "ist nicht vorhanden", "existiert nicht"}
+)
+
+// exitCodeAccessDenied is the exit code of tools that return the Windows error code
+// (ERROR_ACCESS_DENIED)
+const exitCodeAccessDenied = 5
+
+// classifyStepError maps the error of a step to its status
+func classifyStepError(err error) stepStatus {
+	var skip skipError
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return stepApplied
+	case errors.As(err, &skip):
+		return stepSkipped
+	case errors.Is(err, os.ErrPermission):
+		return stepRequiresAdmin
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == exitCodeAccessDenied:
+		return stepRequiresAdmin
+	}
+	message := strings.ToLower(err.Error())
+	for _, text := range accessDeniedTexts {
+		if strings.Contains(message, text) {
+			return stepRequiresAdmin
+		}
+	}
+	for _, text := range notFoundTexts {
+		if strings.Contains(message, text) {
+			return stepNotFound
+		}
+	}
+	return stepFailed
+}
+
+// newStepResult builds the result of a step from its error
+func newStepResult(step, target string, err error) StepResult {
+	result := StepResult{Step: step, Target: target, Status: classifyStepError(err)}
+	if err != nil {
+		result.Reason = err.Error()
+	}
+	return result
+}
+
+// stepResults collects the results of a configure run
+type stepResults []StepResult
+
+// add records the result of a step and returns its status
+func (r *stepResults) add(step, target string, err error) stepStatus {
+	result := newStepResult(step, target, err)
+	*r = append(*r, result)
+	return result.Status
+}
+
+// count returns the number of results with the given status
+func (r stepResults) count(status stepStatus) int {
+	n := 0
+	for _, result := range r {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether a step failed or could not run without administrator
+// rights; -configure then exits with code 1. Skipped steps and devices that were not
+// found do not count as failure.
+func (r stepResults) Failed() bool {
+	return r.count(stepFailed)+r.count(stepRequiresAdmin) > 0
+}
+
+// formatTable formats the results as aligned table with a count line
+func (r stepResults) formatTable() string {
+	headers := [3]string{"Step", "Target", "Result"}
+	widths := [3]int{len(headers[0]), len(headers[1]), len(headers[2])}
+	for _, result := range r {
+		for i, value := range [3]string{result.Step, result.Target, result.Status.String()} {
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+		}
+	}
+
+	var b strings.Builder
+	row := func(step, target, status, reason string) {
+		line := fmt.Sprintf("  %-*s  %-*s  %-*s  %s", widths[0], step, widths[1], target, widths[2], status, reason)
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	row(headers[0], headers[1], headers[2], "Reason")
+	row(strings.Repeat("-", widths[0]), strings.Repeat("-", widths[1]), strings.Repeat("-", widths[2]), "------")
+	for _, result := range r {
+		row(result.Step, result.Target, result.Status.String(), result.Reason)
+	}
+	fmt.Fprintf(&b, "  %d applied, %d skipped, %d failed, %d requires admin, %d not found\n",
+		r.count(stepApplied), r.count(stepSkipped), r.count(stepFailed), r.count(stepRequiresAdmin), r.count(stepNotFound))
+	return b.String()
+}
+
+// printSummary prints the summary table of a configure run
+func (r stepResults) printSummary() {
+	fmt.Println("\n=== Configuration Summary ===")
+	printUTF8("%s", r.formatTable())
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"testing"
+)
+
+func TestClassifyStepError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want stepStatus
+	}{
+		{"no error", nil, stepApplied},
+		{"skip", skipped("already %s", "disabled"), stepSkipped},
+		{"wrapped skip", fmt.Errorf("wake timers: %w", skipped("unchanged")), stepSkipped},
+		{"permission", &fs.PathError{Op: "open", Path: "hiberfil.sys", Err: fs.ErrPermission}, stepRequiresAdmin},
+		{"access denied", errors.New("exit status 1: ERROR: Access is denied."), stepRequiresAdmin},
+		{"access denied, German", errors.New("exit status 1: FEHLER: Zugriff verweigert"), stepRequiresAdmin},
+		{"powercfg permission", errors.New("exit status 1: You do not have permission to enable or disable device wake."), stepRequiresAdmin},
+		{"powercfg permission, German", errors.New("exit status 1: Möglicherweise verfügen Sie nicht über die Berechtigung, diesen Vorgang auszuführen."), stepRequiresAdmin},
+		{"elevation", errors.New("fork/exec powercfg: The requested operation requires elevation."), stepRequiresAdmin},
+		{"not found", errors.New("exit status 1: Device not found."), stepNotFound},
+		{"does not exist", errors.New(`exit status 1: ERROR: The system cannot find the file specified. The task "Backup" does not exist.`), stepNotFound},
+		{"not found, German", errors.New("exit status 1: Das Gerät wurde nicht gefunden."), stepNotFound},
+		{"not present, German", errors.New("exit status 1: Die angegebene Aufgabe ist nicht vorhanden."), stepNotFound},
+		{"failed", errors.New("exit status 1: Invalid Parameters -- try \"/?\" for help"), stepFailed},
+		// Names in the output must not change the classification
+		{"account name", errors.New(`exit status 1: The task \Administrator Backup has an invalid trigger.`), stepFailed},
+		{"device name", errors.New("exit status 1: Wake for device Administrator Keyboard failed."), stepFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyStepError(tt.err); got != tt.want {
+				t.Errorf("classifyStepError(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyStepErrorExitCode(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell to produce an exit code")
+	}
+	for code, want := range map[int]stepStatus{exitCodeAccessDenied: stepRequiresAdmin, 1: stepFailed} {
+		err := exec.Command(sh, "-c", fmt.Sprintf("exit %d", code)).Run()
+		if got := classifyStepError(fmt.Errorf("wake device: %w", err)); got != want {
+			t.Errorf("exit code %d: %s, want %s", code, got, want)
+		}
+	}
+}
+
+func TestFormatTable(t *testing.T) {
+	results := stepResults{
+		newStepResult("disable wake", "Intel(R) Ethernet", nil),
+		newStepResult("wake timers", "AC", skipped("already disabled")),
+		newStepResult("enable wake", "HID-Tastatur für Büro", errors.New("Access is denied.")),
+	}
+	// Columns are aligned by characters, not bytes
+	want := "" +
+		"  Step          Target                 Result          Reason\n" +
+		"  ------------  ---------------------  --------------  ------\n" +
+		"  disable wake  Intel(R) Ethernet      applied\n" +
+		"  wake timers   AC                     skipped         already disabled\n" +
+		"  enable wake   HID-Tastatur für Büro  requires admin  Access is denied.\n" +
+		"  1 applied, 1 skipped, 0 failed, 1 requires admin, 0 not found\n"
+	if got := results.formatTable(); got != want {
+		t.Errorf("formatTable =\n%s\nwant\n%s", got, want)
+	}
+	if !results.Failed() {
+		t.Error("Failed = false with a step requiring admin")
+	}
+	if (stepResults{results[0], results[1]}).Failed() {
+		t.Error("Failed = true with applied and skipped steps only")
+	}
+
+	empty := stepResults{}.formatTable()
+	if want := "  Step  Target  Result  Reason\n  ----  ------  ------  ------\n  0 applied, 0 skipped, 0 failed, 0 requires admin, 0 not found\n"; empty != want {
+		t.Errorf("empty formatTable =\n%q\nwant\n%q", empty, want)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WakeTask is a scheduled task that is allowed to wake the computer (WakeToRun)
+type WakeTask struct {
+	Path     string // full task path, e.g. \Microsoft\Windows\UpdateOrchestrator\Reboot
+	Author   string
+	Enabled  bool
+	Triggers []string // readable trigger descriptions
+	NextRun  time.Time
+}
+
+// Name returns the task name without its folder
+func (t WakeTask) Name() string {
+	return t.Path[strings.LastIndex(t.Path, `\`)+1:]
+}
+
+// IsWindowsUpdate reports whether the task belongs to Windows Update (Update
+// Orchestrator). These tasks are protected and commonly wake computers at night.
+func (t WakeTask) IsWindowsUpdate() bool {
+	path := strings.ToLower(t.Path)
+	return strings.Contains(path, `\microsoft\windows\updateorchestrator\`) ||
+		strings.Contains(path, `\microsoft\windows\windowsupdate\`)
+}
+
+// taskXML is the part of a Task Scheduler task definition SleepRight needs
+type taskXML struct {
+	RegistrationInfo struct {
+		Author string `xml:"Author"`
+		URI    string `xml:"URI"`
+	} `xml:"RegistrationInfo"`
+	Triggers struct {
+		Items []taskTriggerXML `xml:",any"`
+	} `xml:"Triggers"`
+	Settings struct {
+		WakeToRun bool   `xml:"WakeToRun"`
+		Enabled   string `xml:"Enabled"`
+	} `xml:"Settings"`
+}
+
+// taskTriggerXML is any trigger element (TimeTrigger, CalendarTrigger, BootTrigger, ...)
+type taskTriggerXML struct {
+	XMLName       xml.Name
+	StartBoundary string `xml:"StartBoundary"`
+	Enabled       string `xml:"Enabled"`
+	Repetition    struct {
+		Interval string `xml:"Interval"`
+	} `xml:"Repetition"`
+	ScheduleByDay            *struct{} `xml:"ScheduleByDay"`
+	ScheduleByWeek           *struct{} `xml:"ScheduleByWeek"`
+	ScheduleByMonth          *struct{} `xml:"ScheduleByMonth"`
+	ScheduleByMonthDayOfWeek *struct{} `xml:"ScheduleByMonthDayOfWeek"`
+}
+
+// parseWakeTasks parses "schtasks /query /xml ONE" output and returns the tasks with
+// WakeToRun set. All tasks are concatenated in one <Tasks> element, each preceded by
+// a comment with its path:
+//
+//	<Tasks>
+//	  <!-- \Microsoft\Windows\UpdateOrchestrator\Reboot -->
+//	  <Task version="1.2" xmlns="..."> ... </Task>
+//
+// The declared encoding (UTF-16) does not match the piped output, so the input is
+// passed through unchanged.
+func parseWakeTasks(output string) ([]WakeTask, error) {
+	decoder := xml.NewDecoder(strings.NewReader(output))
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	decoder.Strict = false
+
+	var tasks []WakeTask
+	lastComment := ""
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return tasks, fmt.Errorf("Fehler beim Lesen der Aufgabenliste: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.Comment:
+			lastComment = strings.TrimSpace(string(t))
+		case xml.StartElement:
+			if t.Name.Local != "Task" {
+				continue
+			}
+			var task taskXML
+			if err := decoder.DecodeElement(&task, &t); err != nil {
+				return tasks, fmt.Errorf("Fehler beim Lesen der Aufgabe %s: %w", lastComment, err)
+			}
+			if task.Settings.WakeToRun {
+				path := lastComment
+				if path == "" {
+					path = task.RegistrationInfo.URI
+				}
+				tasks = append(tasks, WakeTask{
+					Path:     decodeCP1252(path),
+					Author:   decodeCP1252(strings.TrimSpace(task.RegistrationInfo.Author)),
+					Enabled:  task.Settings.Enabled != "false",
+					Triggers: describeTaskTriggers(task.Triggers.Items),
+				})
+			}
+			lastComment = ""
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return strings.ToLower(tasks[i].Path) < strings.ToLower(tasks[j].Path) })
+	return tasks, nil
+}
+
+// describeTaskTriggers returns a short German description of each enabled trigger
+func describeTaskTriggers(triggers []taskTriggerXML) []string {
+	var descriptions []string
+	for _, trigger := range triggers {
+		if trigger.Enabled == "false" {
+			continue
+		}
+		var description string
+		switch trigger.XMLName.Local {
+		case "CalendarTrigger":
+			switch {
+			case trigger.ScheduleByDay != nil:
+				description = "täglich"
+			case trigger.ScheduleByWeek != nil:
+				description = "wöchentlich"
+			case trigger.ScheduleByMonth != nil, trigger.ScheduleByMonthDayOfWeek != nil:
+				description = "monatlich"
+			default:
+				description = "nach Kalender"
+			}
+		case "TimeTrigger":
+			description = "einmalig"
+		case "BootTrigger":
+			description = "beim Systemstart"
+		case "LogonTrigger":
+			description = "bei Anmeldung"
+		case "IdleTrigger":
+			description = "im Leerlauf"
+		case "EventTrigger":
+			description = "bei Ereignis"
+		case "RegistrationTrigger":
+			description = "bei Registrierung"
+		case "SessionStateChangeTrigger":
+			description = "bei Sitzungswechsel"
+		default:
+			description = trigger.XMLName.Local
+		}
+		if start, err := time.Parse("2006-01-02T15:04:05", trimTimeZone(trigger.StartBoundary)); err == nil {
+			if trigger.XMLName.Local == "TimeTrigger" {
+				description += " am " + start.Format("02.01.2006 15:04")
+			} else {
+				description += " um " + start.Format("15:04")
+			}
+		}
+		if trigger.Repetition.Interval != "" {
+			description += ", Wiederholung " + trigger.Repetition.Interval
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions
+}
+
+// trimTimeZone removes fractional seconds and a time zone offset from an XML date
+func trimTimeZone(value string) string {
+	if len(value) > len("2006-01-02T15:04:05") {
+		return value[:len("2006-01-02T15:04:05")]
+	}
+	return value
+}
+
+// queryWakeTasks lists all scheduled tasks with WakeToRun and their next run time
+func queryWakeTasks() ([]WakeTask, error) {
+	output, err := runCommandWithEncoding("schtasks", "/query", "/xml", "ONE")
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Ausführen von schtasks /query: %w", err)
+	}
+	tasks, err := parseWakeTasks(output)
+	if err != nil {
+		return tasks, err
+	}
+
+	// The next run time is not part of the XML definition
+	nextRuns, err := queryNextRunTimes()
+	if err != nil && verboseFlag {
+		printUTF8ln("Hinweis: Konnte nächste Ausführungszeiten nicht abrufen: %v", err)
+	}
+	for i := range tasks {
+		tasks[i].NextRun = nextRuns[strings.ToLower(tasks[i].Path)]
+	}
+	return tasks, nil
+}
+
+// queryNextRunTimes returns the next run time of all WakeToRun tasks, keyed by the
+// lower-case task path
+func queryNextRunTimes() (map[string]time.Time, error) {
+	script := `Get-ScheduledTask | Where-Object { $_.Settings.WakeToRun } | ForEach-Object { ` +
+		`$info = $_ | Get-ScheduledTaskInfo; if ($info.NextRunTime) { $_.TaskPath + $_.TaskName + '|' + $info.NextRunTime.ToString('o') } }`
+	output, err := runCommandWithEncoding("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	if err != nil {
+		return nil, err
+	}
+	return parseNextRunTimes(output), nil
+}
+
+// parseNextRunTimes parses "<path>|<RFC 3339 time>" lines
+func parseNextRunTimes(output string) map[string]time.Time {
+	nextRuns := make(map[string]time.Time)
+	for _, line := range strings.Split(output, "\n") {
+		path, value, found := strings.Cut(strings.TrimSpace(line), "|")
+		if !found {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			nextRuns[strings.ToLower(decodeCP1252(path))] = t
+		}
+	}
+	return nextRuns
+}
+
+// showWakeTasks prints all scheduled tasks that may wake the computer
+func showWakeTasks(full bool) error {
+	tasks, err := queryWakeTasks()
+	if err != nil {
+		return err
+	}
+	timers, err := queryWakeTimers()
+	if err != nil && verboseFlag {
+		printUTF8ln("Hinweis: Konnte Aufweck-Zeitgeber nicht abrufen: %v", err)
+	}
+
+	printUTF8ln("\n=== Geplante Aufgaben mit \"Zum Ausführen reaktivieren\" ===")
+	if len(tasks) == 0 {
+		printUTF8ln("Keine Aufgaben dürfen den Computer aufwecken.")
+		return nil
+	}
+	shown := 0
+	for _, task := range tasks {
+		if !full && !task.Enabled {
+			continue
+		}
+		shown++
+		state := ""
+		if !task.Enabled {
+			state = " (deaktiviert)"
+		}
+		printUTF8ln("%s%s", task.Path, state)
+		if task.Author != "" {
+			printUTF8ln("  Autor: %s", task.Author)
+		}
+		if len(task.Triggers) > 0 {
+			printUTF8ln("  Auslöser: %s", strings.Join(task.Triggers, "; "))
+		}
+		if !task.NextRun.IsZero() {
+			printUTF8ln("  Nächste Ausführung: %s", task.NextRun.Local().Format("02.01.2006 15:04"))
+		}
+		if timer := findTimerForTask(timers, task); timer != nil {
+			printUTF8ln("  Aktiver Aufweck-Zeitgeber: läuft ab %s", timer.Expires.Format("02.01.2006 15:04"))
+		}
+		if task.IsWindowsUpdate() {
+			printUTF8ln("  Hinweis: Windows Update (Update Orchestrator) - geschützte Aufgabe, weckt häufig nachts.")
+			printUTF8ln("           Abhilfe über Gruppenrichtlinie/Nutzungszeit oder \"wake-timers: important\" im Profil.")
+		}
+	}
+	if shown < len(tasks) {
+		printUTF8ln("(%d deaktivierte Aufgaben ausgeblendet, -info-full zeigt alle)", len(tasks)-shown)
+	}
+	printUTF8ln("Abhilfe für einzelne Aufgaben: SleepRight tasks disable <Name oder Muster>")
+	return nil
+}
+
+// taskPatternRegexp converts a task name pattern with * and ? wildcards into a
+// case-insensitive regular expression
+func taskPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.Compile("(?i)^" + quoted + "$")
+}
+
+// matchWakeTasks returns the tasks whose full path or name matches the pattern
+func matchWakeTasks(tasks []WakeTask, pattern string) ([]WakeTask, error) {
+	re, err := taskPatternRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	var matched []WakeTask
+	for _, task := range tasks {
+		if re.MatchString(task.Path) || re.MatchString(task.Name()) {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+// disableTaskWake clears WakeToRun of a task via the ScheduledTasks PowerShell module;
+// schtasks /change cannot change this setting
+func disableTaskWake(task WakeTask) error {
+	folder := task.Path[:len(task.Path)-len(task.Name())]
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+	script := fmt.Sprintf(`$ErrorActionPreference = 'Stop'; `+
+		`$settings = (Get-ScheduledTask -TaskPath %[1]s -TaskName %[2]s).Settings; $settings.WakeToRun = $false; `+
+		`Set-ScheduledTask -TaskPath %[1]s -TaskName %[2]s -Settings $settings | Out-Null`, quote(folder), quote(task.Name()))
+	change := auditChange{Action: "task", Target: task.Path, Previous: "WakeToRun", New: "no WakeToRun"}
+	return runAudited(change, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+}
+
+// runTasksCommand lists wake tasks or disables WakeToRun for the tasks matching the
+// given names or patterns
+func runTasksCommand(args []string) int {
+	if len(args) == 0 {
+		if err := showWakeTasks(true); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if args[0] != "disable" || len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: SleepRight tasks [disable <name|pattern>...]\n")
+		return 1
+	}
+
+	tasks, err := queryWakeTasks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	exitCode := 0
+	for _, pattern := range args[1:] {
+		matched, err := matchWakeTasks(tasks, pattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = 1
+			continue
+		}
+		if len(matched) == 0 {
+			fmt.Fprintf(os.Stderr, "No wake task matches %q\n", pattern)
+			exitCode = 1
+			continue
+		}
+		for _, task := range matched {
+			if err := disableTaskWake(task); err != nil {
+				printUTF8ln("Failed to disable wake for %s: %v", task.Path, err)
+				if task.IsWindowsUpdate() {
+					printUTF8ln("  Windows Update tasks are protected; use \"wake-timers: important\" or Windows Update policies instead.")
+				}
+				exitCode = 1
+				continue
+			}
+			printUTF8ln("Wake disabled for %s", task.Path)
+		}
+	}
+	return exitCode
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWakeTasks(t *testing.T) {
+	tasks, err := parseWakeTasks(readFixture(t, "schtasks-query.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only tasks with WakeToRun, sorted by path
+	want := []WakeTask{
+		{
+			Path:     `\BackupNow`,
+			Author:   `PC\jan`,
+			Enabled:  true,
+			Triggers: []string{"täglich um 03:00, Wiederholung PT1H"},
+		},
+		{
+			Path:    `\Microsoft\Windows\Media Center\mcupdate`,
+			Author:  "Microsoft Corporation",
+			Enabled: true,
+			// The disabled boot trigger is left out
+			Triggers: []string{"wöchentlich um 04:00", "bei Anmeldung"},
+		},
+		{
+			Path:     `\Microsoft\Windows\UpdateOrchestrator\Reboot`,
+			Author:   `$(@%SystemRoot%\system32\MusNotification.exe,-600)`,
+			Enabled:  false,
+			Triggers: []string{"einmalig am 20.10.2026 03:15"},
+		},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("parseWakeTasks =\n%+v\nwant\n%+v", tasks, want)
+	}
+
+	names := make([]string, len(tasks))
+	for i, task := range tasks {
+		names[i] = task.Name()
+	}
+	if got := strings.Join(names, "|"); got != "BackupNow|mcupdate|Reboot" {
+		t.Errorf("names = %s", got)
+	}
+	if tasks[0].IsWindowsUpdate() || tasks[1].IsWindowsUpdate() || !tasks[2].IsWindowsUpdate() {
+		t.Errorf("IsWindowsUpdate = %t %t %t, want false false true",
+			tasks[0].IsWindowsUpdate(), tasks[1].IsWindowsUpdate(), tasks[2].IsWindowsUpdate())
+	}
+}
+
+func TestMatchWakeTasks(t *testing.T) {
+	tasks, err := parseWakeTasks(readFixture(t, "schtasks-query.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pattern string
+		want    string // matched names
+	}{
+		{"Reboot", "Reboot"},
+		{"reboot", "Reboot"},
+		{"Re?oot", "Reboot"},
+		{`\Microsoft\Windows\UpdateOrchestrator\*`, "Reboot"},
+		{`\BackupNow`, "BackupNow"}, // task in the root folder
+		{"Backup*", "BackupNow"},
+		{"*update*", "mcupdate|Reboot"}, // the path of Reboot contains UpdateOrchestrator
+		{`\Microsoft\*`, "mcupdate|Reboot"},
+		{"*", "BackupNow|mcupdate|Reboot"},
+		// Patterns match the whole name or path, not a part of it
+		{"boot", ""},
+		{"Microsoft", ""},
+		{`\Microsoft\Windows`, ""},
+		{"Backup", ""},
+		{"Schedule Scan", ""}, // no WakeToRun
+	}
+	for _, tt := range tests {
+		matched, err := matchWakeTasks(tasks, tt.pattern)
+		if err != nil {
+			t.Errorf("matchWakeTasks(%q): %v", tt.pattern, err)
+			continue
+		}
+		names := make([]string, len(matched))
+		for i, task := range matched {
+			names[i] = task.Name()
+		}
+		if got := strings.Join(names, "|"); got != tt.want {
+			t.Errorf("matchWakeTasks(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timelineGlyphs are the characters used to draw the sleep timeline
+type timelineGlyphs struct {
+	asleep  rune
+	awake   rune
+	unknown rune
+	border  rune
+}
+
+var (
+	unicodeTimelineGlyphs = timelineGlyphs{asleep: '█', awake: '░', unknown: ' ', border: '│'}
+	asciiTimelineGlyphs   = timelineGlyphs{asleep: '#', awake: '.', unknown: ' ', border: '|'}
+)
+
+// timelineLabelWidth is the width of the day label in front of every row ("Mo 15.12. ")
+const timelineLabelWidth = 10
+
+// timelineSlotCounts are the supported numbers of slots per day (largest first);
+// each divides a day into whole minutes and keeps the hour ticks aligned
+var timelineSlotCounts = []int{144, 96, 72, 48, 24}
+
+var germanWeekdays = []string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"}
+
+// timelineSlotState is the state of the system during one slot
+type timelineSlotState int
+
+const (
+	slotUnknown timelineSlotState = iota
+	slotAwake
+	slotAsleep
+)
+
+// timelineInterval is a period in which the system state is known
+type timelineInterval struct {
+	start, end time.Time
+	state      timelineSlotState
+}
+
+// renderSleepTimeline draws a chart with one row per day for the last days (oldest
+// first). Every column is a time slot marked asleep, awake or unknown; the slot in
+// which a wake happened shows the letter of its wake source instead. The chart
+// fits into width console columns. The legend lines follow the chart rows.
+func renderSleepTimeline(events []WakeEvent, days, width int, now time.Time, glyphs timelineGlyphs) []string {
+	if days < 1 {
+		days = 1
+	}
+	slots := timelineSlotCounts[len(timelineSlotCounts)-1]
+	for _, count := range timelineSlotCounts {
+		if timelineLabelWidth+count+2 <= width {
+			slots = count
+			break
+		}
+	}
+	slotLength := 24 * time.Hour / time.Duration(slots)
+	slotMinutes := 24 * 60 / slots
+
+	// Sort a copy of the events by wake time (oldest first)
+	sorted := make([]WakeEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// Known intervals: asleep between sleep start and wake, awake between a wake and the
+	// next sleep start, and awake from the last wake until now
+	var intervals []timelineInterval
+	for i, event := range sorted {
+		intervals = append(intervals, timelineInterval{start: event.SleepTime, end: event.Timestamp, state: slotAsleep})
+		awakeEnd := now
+		if i+1 < len(sorted) {
+			awakeEnd = sorted[i+1].SleepTime
+		}
+		if awakeEnd.After(event.Timestamp) {
+			intervals = append(intervals, timelineInterval{start: event.Timestamp, end: awakeEnd, state: slotAwake})
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	firstDay := today.AddDate(0, 0, -(days - 1))
+
+	// Assign a letter to every wake source shown in the chart in order of first appearance
+	letters := make(map[string]rune)
+	var legendSources []string
+	for _, event := range sorted {
+		if event.Timestamp.Before(firstDay) {
+			continue
+		}
+		source := strings.TrimSpace(event.Source)
+		if _, ok := letters[source]; !ok && len(letters) < 26 {
+			letters[source] = rune('A' + len(letters))
+			legendSources = append(legendSources, source)
+		}
+	}
+
+	var lines []string
+	lines = append(lines, strings.Repeat(" ", timelineLabelWidth+1)+timelineHourTicks(slots))
+
+	for d := 0; d < days; d++ {
+		dayStart := firstDay.AddDate(0, 0, d)
+		row := make([]rune, slots)
+		for s := range row {
+			// Slots follow the local clock, so days with a DST change still have one
+			// slot per wall-clock interval
+			slotStart := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), 0, s*slotMinutes, 0, 0, dayStart.Location())
+			mid := slotStart.Add(slotLength / 2)
+			state := slotUnknown
+			if !mid.After(now) {
+				for _, interval := range intervals {
+					if !mid.Before(interval.start) && mid.Before(interval.end) {
+						state = interval.state
+						break
+					}
+				}
+			}
+			switch state {
+			case slotAsleep:
+				row[s] = glyphs.asleep
+			case slotAwake:
+				row[s] = glyphs.awake
+			default:
+				row[s] = glyphs.unknown
+			}
+		}
+		// Mark the wake slots with the letter of the wake source
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		for _, event := range sorted {
+			wake := event.Timestamp.In(now.Location())
+			if wake.Before(dayStart) || !wake.Before(dayEnd) {
+				continue
+			}
+			if letter, ok := letters[strings.TrimSpace(event.Source)]; ok {
+				// A DST day has 23 or 25 hours; take the slot from the clock time
+				row[min((wake.Hour()*60+wake.Minute())/slotMinutes, len(row)-1)] = letter
+			}
+		}
+
+		label := fmt.Sprintf("%s %s", germanWeekdays[dayStart.Weekday()], dayStart.Format("02.01."))
+		lines = append(lines, fmt.Sprintf("%-*s%c%s%c", timelineLabelWidth, label, glyphs.border, string(row), glyphs.border))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("%c = schläft   %c = wach   '%c' = unbekannt   (1 Zeichen = %d Minuten)",
+		glyphs.asleep, glyphs.awake, glyphs.unknown, int(slotLength.Minutes())))
+	for _, source := range legendSources {
+		lines = append(lines, fmt.Sprintf("%c = Aufgeweckt durch: %s", letters[source], source))
+	}
+	return lines
+}
+
+// timelineHourTicks returns the header line with hour labels every three hours
+func timelineHourTicks(slots int) string {
+	ticks := []rune(strings.Repeat(" ", slots+1))
+	for hour := 0; hour < 24; hour += 3 {
+		label := fmt.Sprintf("%d", hour)
+		pos := hour * slots / 24
+		for i, r := range label {
+			if pos+i < len(ticks) {
+				ticks[pos+i] = r
+			}
+		}
+	}
+	return strings.TrimRight(string(ticks), " ")
+}
+
+// showSleepTimeline prints the sleep timeline for the last days. Box characters are
+// only used when the console can display them, otherwise the chart falls back to ASCII.
+func showSleepTimeline(events []WakeEvent, days int) {
+	printUTF8ln("\n=== Schlaf-Zeitleiste (letzte %d Tage) ===", days)
+
+	width := consoleWidth()
+	if timelineWidth > 0 {
+		width = timelineWidth
+	}
+	glyphs := unicodeTimelineGlyphs
+	sample := string([]rune{glyphs.asleep, glyphs.awake, glyphs.border})
+	if !consoleIsUTF8() && !canEncodeCP1252(sample) {
+		glyphs = asciiTimelineGlyphs
+	}
+
+	for _, line := range renderSleepTimeline(events, days, width, time.Now(), glyphs) {
+		printUTF8ln("%s", line)
+	}
+}
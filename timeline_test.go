@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// timelineRow returns the chart cells of the row whose label starts with day
+func timelineRow(t *testing.T, lines []string, day string) []rune {
+	t.Helper()
+	for _, line := range lines {
+		if strings.HasPrefix(line, day) {
+			cells := []rune(line)[timelineLabelWidth+1:]
+			return cells[:len(cells)-1]
+		}
+	}
+	t.Fatalf("no row for %s in\n%s", day, strings.Join(lines, "\n"))
+	return nil
+}
+
+func TestRenderSleepTimelineFallBackDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 26.10.2025 has 25 hours: the clocks go back from 03:00 to 02:00
+	events := []WakeEvent{
+		{
+			SleepTime: time.Date(2025, 10, 26, 1, 0, 0, 0, berlin),
+			// 02:30 after the clocks went back (01:30 UTC, not 00:30 UTC)
+			Timestamp: time.Date(2025, 10, 26, 1, 30, 0, 0, time.UTC).In(berlin),
+			Source:    "Timer",
+		},
+		{
+			// More than 24 hours after midnight, the 25th hour of the day
+			SleepTime: time.Date(2025, 10, 26, 22, 0, 0, 0, berlin),
+			Timestamp: time.Date(2025, 10, 26, 23, 30, 0, 0, berlin),
+			Source:    "Keyboard",
+		},
+	}
+	now := time.Date(2025, 10, 27, 8, 0, 0, 0, berlin)
+
+	lines := renderSleepTimeline(events, 2, 200, now, asciiTimelineGlyphs)
+	row := timelineRow(t, lines, "So 26.10.")
+	if len(row) != 144 {
+		t.Fatalf("row has %d slots, want 144", len(row))
+	}
+	if row[(2*60+30)/10] != 'A' {
+		t.Errorf("wake at 02:30 not in slot 15: %q", string(row))
+	}
+	if row[(23*60+30)/10] != 'B' {
+		t.Errorf("wake at 23:30 not in slot 141: %q", string(row))
+	}
+	if row[(22*60+30)/10] != '#' {
+		t.Errorf("22:30 not shown asleep: %q", string(row))
+	}
+}
+
+func TestRenderSleepTimelineSpringForwardDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 30.03.2025 has 23 hours: the clocks go forward from 02:00 to 03:00
+	events := []WakeEvent{{
+		SleepTime: time.Date(2025, 3, 30, 22, 0, 0, 0, berlin),
+		Timestamp: time.Date(2025, 3, 30, 23, 50, 0, 0, berlin),
+		Source:    "Keyboard",
+	}}
+	now := time.Date(2025, 3, 31, 8, 0, 0, 0, berlin)
+
+	row := timelineRow(t, renderSleepTimeline(events, 2, 200, now, asciiTimelineGlyphs), "So 30.03.")
+	if row[len(row)-1] != 'A' {
+		t.Errorf("wake at 23:50 not in the last slot: %q", string(row))
+	}
+	if row[(23*60)/10] != '#' {
+		t.Errorf("23:00 not shown asleep: %q", string(row))
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/text/encoding/charmap"
 )
@@ -35,6 +39,16 @@ func runCommandWithEncoding(name string, args ...string) (string, error) {
 	}
 
 	if err != nil {
+		// powercfg and schtasks explain errors on stdout or stderr; keep the text so
+		// the error can be classified
+		text := strings.TrimSpace(decodeCP1252(string(output)))
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			text = strings.TrimSpace(decodeCP1252(string(exitErr.Stderr)))
+		}
+		if text != "" {
+			err = fmt.Errorf("%w: %s", err, text)
+		}
 		return "", err
 	}
 	// Return output as-is in Windows codepage (CP1252), do NOT convert to UTF-8
@@ -61,8 +75,45 @@ func printUTF8(format string, args ...interface{}) {
 	fmt.Print(cp1252Bytes)
 }
 
+// decodeCP1252 converts command output in Windows codepage (CP1252) to UTF-8 for
+// files such as reports. Strings that already are valid UTF-8 are returned as-is.
+func decodeCP1252(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	decoded, err := charmap.Windows1252.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// canEncodeCP1252 reports whether s can be printed through printUTF8 without loss
+func canEncodeCP1252(s string) bool {
+	_, err := charmap.Windows1252.NewEncoder().String(s)
+	return err == nil
+}
+
 // printUTF8ln converts UTF-8 string to Windows codepage (CP1252) and prints it with newline
 func printUTF8ln(format string, args ...interface{}) {
 	printUTF8(format, args...)
 	fmt.Println()
 }
+
+// dataDir returns the directory where SleepRight keeps its persistent state
+// (%ProgramData%\SleepRight) and creates it if necessary
+func dataDir() (string, error) {
+	base := os.Getenv("ProgramData")
+	if base == "" {
+		var err error
+		base, err = os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine data directory: %w", err)
+		}
+	}
+	dir := filepath.Join(base, "SleepRight")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create data directory %s: %w", dir, err)
+	}
+	return dir, nil
+}
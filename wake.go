@@ -9,12 +9,17 @@ import (
 
 // WakeEvent represents a wake event from Windows
 type WakeEvent struct {
-	Timestamp time.Time
+	Timestamp time.Time // Reaktivierungszeit
+	SleepTime time.Time // Zeit im Energiesparmodus
 	Reason    string
 	Device    string
-	Source    string
+	Source    string // Reaktivierungsquelle
 }
 
+// wakeEventQueryCount is the number of Power-Troubleshooter events read from the
+// event log. Anomaly detection needs more history than the ten events displayed.
+const wakeEventQueryCount = 100
+
 func showWakeEvents(full bool) error {
 	// Try to get wake events from powercfg
 	outputStr, err := runCommandWithEncoding("powercfg", "/lastwake")
@@ -23,7 +28,7 @@ func showWakeEvents(full bool) error {
 	}
 
 	printUTF8ln("Letztes Aufweck-Ereignis:")
-	printUTF8ln(outputStr)
+	printUTF8ln("%s", outputStr)
 
 	// Check if lastwake shows no results (common Windows 11 issue)
 	if strings.Contains(outputStr, "Wake History Count - 0") ||
@@ -47,6 +52,13 @@ func showWakeEvents(full bool) error {
 		}
 	}
 
+	// Show scheduled tasks allowed to wake the system (WakeToRun)
+	if err := showWakeTasks(full); err != nil {
+		if verboseFlag {
+			printUTF8ln("Hinweis: Konnte geplante Aufgaben nicht abrufen: %v", err)
+		}
+	}
+
 	// Show power requests (what prevents sleep)
 	if err := showPowerRequests(full); err != nil {
 		if verboseFlag {
@@ -132,29 +144,49 @@ func showAvailableSleepStates(full bool) error {
 		}
 	}
 	
-	printUTF8ln(strings.Join(outputLines, "\n"))
+	printUTF8ln("%s", strings.Join(outputLines, "\n"))
 
 	// Check for Modern Standby (S0 Low Power Idle)
 	if strings.Contains(outputStr, "S0 Low Power Idle") || strings.Contains(outputStr, "S0 Niedriger Energieverbrauch") {
 		printUTF8ln("Warnung: Modern Standby (S0 Low Power Idle) ist aktiv. Der PC schläft möglicherweise nie vollständig und kann unerwartet aufwachen.")
+		printUTF8ln("Abhilfe: \"SleepRight set standby-network disabled\" trennt das Netzwerk im Standby,")
+		printUTF8ln("         \"SleepRight modern-standby\" zeigt weitere Möglichkeiten.")
 	}
 
 	return nil
 }
 
-// showWakeTimers shows scheduled tasks/timers that can wake the system
+// showWakeTimers shows scheduled tasks/timers that can wake the system, ordered by
+// their expiry time
 func showWakeTimers(full bool) error {
-	outputStr, err := runCommandWithEncoding("powercfg", "/waketimers")
+	timers, err := queryWakeTimers()
 	if err != nil {
-		return fmt.Errorf("Fehler beim Ausführen von powercfg /waketimers: %w", err)
+		return err
 	}
 
 	printUTF8ln("\n=== Aufweck-Zeitgeber (Geplante Aufgaben) ===")
-	printUTF8ln(outputStr)
+	alias := findSettingAlias("wake-timers")
+	if setting, err := queryCatalogSetting(alias); err == nil {
+		printUTF8ln("Zeitgeber zur Aktivierung: Netzbetrieb %s | Batterie %s",
+			describeWakeTimerPolicy(setting.AC, setting.HasAC), describeWakeTimerPolicy(setting.DC, setting.HasDC))
+	} else if verboseFlag {
+		printUTF8ln("Hinweis: %v", err)
+	}
+	for _, timer := range timers {
+		expires := "unbekannt"
+		if !timer.Expires.IsZero() {
+			expires = timer.Expires.Format("02.01.2006 15:04:05")
+		}
+		printUTF8ln("%s  [%s] %s", expires, timer.OwnerType, timer.Owner)
+		if timer.TaskPath != "" {
+			printUTF8ln("  Aufgabe: %s", timer.TaskPath)
+		} else if timer.Reason != "" {
+			printUTF8ln("  Ursache: %s", timer.Reason)
+		}
+	}
 
 	// Check if there are active wake timers
-	hasTimers := strings.Contains(outputStr, "Ursache:") || strings.Contains(outputStr, "Cause:")
-	if hasTimers {
+	if len(timers) > 0 {
 		printUTF8ln("Warnung: Aktive Aufweck-Zeitgeber gefunden! Diese geplanten Aufgaben können den PC aus dem Ruhemodus wecken.")
 	} else if full {
 		printUTF8ln("Keine aktiven Aufweck-Zeitgeber gefunden.")
@@ -163,6 +195,23 @@ func showWakeTimers(full bool) error {
 	return nil
 }
 
+// describeWakeTimerPolicy returns the German description of a wake timer policy value
+func describeWakeTimerPolicy(value uint32, found bool) string {
+	if !found {
+		return "unbekannt"
+	}
+	switch value {
+	case 0:
+		return "deaktiviert"
+	case 1:
+		return "aktiviert"
+	case 2:
+		return "nur wichtige Zeitgeber"
+	default:
+		return fmt.Sprintf("%d", value)
+	}
+}
+
 // showPowerRequests shows what prevents the system from entering sleep
 func showPowerRequests(full bool) error {
 	outputStr, err := runCommandWithEncoding("powercfg", "/requests")
@@ -237,7 +286,7 @@ func showPowerRequests(full bool) error {
 	if len(filteredLines) > 0 || full {
 		printUTF8ln("\n=== Energieanfragen (Was verhindert Ruhezustand) ===")
 		if len(filteredLines) > 0 {
-			printUTF8ln(strings.Join(filteredLines, "\n"))
+			printUTF8ln("%s", strings.Join(filteredLines, "\n"))
 		}
 		
 		if hasRequests {
@@ -254,25 +303,83 @@ func showPowerRequests(full bool) error {
 func showEventLogWakeEvents(full bool) error {
 	printUTF8ln("\n=== Ereignisprotokoll-Analyse (Power-Troubleshooter) ===")
 
+	events, err := queryEventLogWakeEvents(wakeEventQueryCount)
+	if err != nil {
+		// If wevtutil fails, try alternative method
+		return showEventLogAlternative()
+	}
+
+	// Filter events by time (only last 24 hours if not full)
+	now := time.Now()
+	var filteredEvents []WakeEvent
+	for _, event := range events {
+		if full || event.Timestamp.After(now.Add(-24*time.Hour)) {
+			filteredEvents = append(filteredEvents, event)
+		}
+	}
+
+	// Display events in clean format with sleep duration
+	if len(filteredEvents) > 0 {
+		printUTF8ln("Aufweck-Ereignisse aus dem Ereignisprotokoll (neueste zuerst):")
+		maxEvents := 10
+		if len(filteredEvents) < maxEvents {
+			maxEvents = len(filteredEvents)
+		}
+		for i := 0; i < maxEvents; i++ {
+			event := filteredEvents[i]
+			// Convert UTC times to local time for display
+			wakeTimeLocal := event.Timestamp.Local()
+			sleepTimeLocal := event.SleepTime.Local()
+			wakeTimeFormatted := wakeTimeLocal.Format("02.01.2006 15:04:05")
+			sleepTimeFormatted := sleepTimeLocal.Format("02.01.2006 15:04:05")
+
+			printUTF8ln("  %d. Aufwachzeit: %s", i+1, wakeTimeFormatted)
+			printUTF8ln("     Schlafbeginn: %s", sleepTimeFormatted)
+			printUTF8ln("     Quelle: %s", event.Source)
+
+			// Calculate sleep duration (difference between wake time and sleep time in the same event)
+			duration := event.Timestamp.Sub(event.SleepTime)
+			sleepDuration := formatDuration(duration)
+			printUTF8ln("     Schlafdauer: %s", sleepDuration)
+
+			if i < maxEvents-1 {
+				printUTF8ln("")
+			}
+		}
+	} else {
+		if full {
+			printUTF8ln("Keine Power-Troubleshooter-Ereignisse im Ereignisprotokoll gefunden.")
+		}
+	}
+
+	if timelineFlag {
+		showSleepTimeline(events, timelineDays)
+	}
+
+	// Compare all events against the machine's baseline to flag unusual wake patterns
+	showWakeAnomalies(events, full)
+
+	return nil
+}
+
+// queryEventLogWakeEvents reads up to maxEvents Power-Troubleshooter events from the
+// System event log and returns the parsed wake events (newest first)
+func queryEventLogWakeEvents(maxEvents int) ([]WakeEvent, error) {
 	// Query Event Log for Power-Troubleshooter events (Event ID 1)
 	// This shows wake source information
-	// Increase limit to get more events for better sorting
-	cmd := exec.Command("wevtutil", "qe", "System", "/q:*[System[Provider[@Name='Microsoft-Windows-Power-Troubleshooter']]]", "/f:text", "/c:20", "/rd:true")
+	cmd := exec.Command("wevtutil", "qe", "System", "/q:*[System[Provider[@Name='Microsoft-Windows-Power-Troubleshooter']]]", "/f:text", fmt.Sprintf("/c:%d", maxEvents), "/rd:true")
 	output, err := cmd.Output()
 	if err != nil {
-		// If wevtutil fails, try alternative method
-		return showEventLogAlternative()
+		return nil, fmt.Errorf("Fehler beim Ausführen von wevtutil: %w", err)
 	}
 
-	outputStr := string(output) // Keep in Windows codepage, do not convert
+	return parseEventLogWakeEvents(string(output)), nil // Keep in Windows codepage, do not convert
+}
 
-	// Parse and display relevant wake events in a clean format
+// parseEventLogWakeEvents parses the text output of wevtutil for Power-Troubleshooter
+// events and returns them sorted by wake time (newest first)
+func parseEventLogWakeEvents(outputStr string) []WakeEvent {
 	lines := strings.Split(outputStr, "\n")
-	type WakeEvent struct {
-		sleepTime time.Time // Zeit im Energiesparmodus
-		wakeTime  time.Time // Reaktivierungszeit
-		source    string    // Reaktivierungsquelle
-	}
 	var events []WakeEvent
 
 	// Parse events - look for both sleep time and wake time in the same event block
@@ -374,9 +481,9 @@ func showEventLogWakeEvents(full bool) error {
 				// Verify that wake time is after sleep time (sanity check)
 				if wakeTime.After(sleepTime) {
 					events = append(events, WakeEvent{
-						sleepTime: sleepTime,
-						wakeTime:  wakeTime,
-						source:    sourceStr,
+						Timestamp: wakeTime,
+						SleepTime: sleepTime,
+						Source:    sourceStr,
 					})
 				}
 			}
@@ -387,57 +494,14 @@ func showEventLogWakeEvents(full bool) error {
 	// Simple bubble sort: if event[i] wake time is older than event[j] wake time, swap them
 	for i := 0; i < len(events); i++ {
 		for j := i + 1; j < len(events); j++ {
-			if events[i].wakeTime.Before(events[j].wakeTime) {
+			if events[i].Timestamp.Before(events[j].Timestamp) {
 				// i is older than j, so swap to put j (newer) first
 				events[i], events[j] = events[j], events[i]
 			}
 		}
 	}
 
-	// Filter events by time (only last 24 hours if not full)
-	now := time.Now()
-	var filteredEvents []WakeEvent
-	for _, event := range events {
-		if full || event.wakeTime.After(now.Add(-24*time.Hour)) {
-			filteredEvents = append(filteredEvents, event)
-		}
-	}
-
-	// Display events in clean format with sleep duration
-	if len(filteredEvents) > 0 {
-		printUTF8ln("Aufweck-Ereignisse aus dem Ereignisprotokoll (neueste zuerst):")
-		maxEvents := 10
-		if len(filteredEvents) < maxEvents {
-			maxEvents = len(filteredEvents)
-		}
-		for i := 0; i < maxEvents; i++ {
-			event := filteredEvents[i]
-			// Convert UTC times to local time for display
-			wakeTimeLocal := event.wakeTime.Local()
-			sleepTimeLocal := event.sleepTime.Local()
-			wakeTimeFormatted := wakeTimeLocal.Format("02.01.2006 15:04:05")
-			sleepTimeFormatted := sleepTimeLocal.Format("02.01.2006 15:04:05")
-			
-			printUTF8ln("  %d. Aufwachzeit: %s", i+1, wakeTimeFormatted)
-			printUTF8ln("     Schlafbeginn: %s", sleepTimeFormatted)
-			printUTF8ln("     Quelle: %s", event.source)
-			
-			// Calculate sleep duration (difference between wake time and sleep time in the same event)
-			duration := event.wakeTime.Sub(event.sleepTime)
-			sleepDuration := formatDuration(duration)
-			printUTF8ln("     Schlafdauer: %s", sleepDuration)
-			
-			if i < maxEvents-1 {
-				printUTF8ln("")
-			}
-		}
-	} else {
-		if full {
-			printUTF8ln("Keine Power-Troubleshooter-Ereignisse im Ereignisprotokoll gefunden.")
-		}
-	}
-
-	return nil
+	return events
 }
 
 // formatDuration formats a duration in a human-readable format (German)
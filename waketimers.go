@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WakeTimer is an active wake timer as listed by "powercfg /waketimers"
+type WakeTimer struct {
+	OwnerType string // PROCESS, SERVICE, ...
+	Owner     string // executable path and service name
+	Expires   time.Time
+	Reason    string
+	TaskPath  string // scheduled task named in the reason, if any
+}
+
+var (
+	timerOwnerPattern = regexp.MustCompile(`\[([A-Za-z]+)\]\s*(.*?)\s+(?:expires at|festgelegter Zeitgeber)`)
+	timerTimePattern  = regexp.MustCompile(`(\d{1,2}):(\d{2}):(\d{2})(?:\s*([AaPp][Mm]))?`)
+	timerDatePattern  = regexp.MustCompile(`^\D*?(\d{1,4})([./-])(\d{1,2})[./-](\d{1,4})`)
+	timerTaskPattern  = regexp.MustCompile(`['"„“”](?:NT TASK)?(\\[^'"„“”]+)['"„“”]`)
+)
+
+// parseWakeTimers parses "powercfg /waketimers" output (English or German):
+//
+//	Timer set by [SERVICE] \Device\...\svchost.exe (SystemEventsBroker) expires at 3:12:00 AM on 10/19/2026.
+//	  Reason: Windows will execute 'NT TASK\Microsoft\Windows\UpdateOrchestrator\Reboot_AC' scheduled task that requested waking the computer.
+//
+//	Von [SERVICE] \Device\...\svchost.exe (SystemEventsBroker) festgelegter Zeitgeber läuft um 03:12:00 am 19.10.2026 ab.
+//	  Ursache: Windows führt die geplante Aufgabe "\Microsoft\Windows\UpdateOrchestrator\Reboot_AC" aus, ...
+//
+// Dates with dots are day.month.year, with slashes month/day/year and with dashes
+// year-month-day. The timers are sorted by expiry time.
+func parseWakeTimers(output string, loc *time.Location) []WakeTimer {
+	var timers []WakeTimer
+	var current *WakeTimer
+	inReason := false
+	for _, rawLine := range strings.Split(decodeCP1252(output), "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			inReason = false
+			continue
+		}
+
+		if m := timerOwnerPattern.FindStringSubmatchIndex(line); m != nil {
+			// The owner path may contain digits, so the expiry is only searched after it
+			timers = append(timers, WakeTimer{
+				OwnerType: line[m[2]:m[3]],
+				Owner:     line[m[4]:m[5]],
+				Expires:   parseTimerExpiry(line[m[1]:], loc),
+			})
+			current = &timers[len(timers)-1]
+			inReason = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if label, text, found := strings.Cut(line, ":"); found && (strings.EqualFold(label, "Reason") || strings.EqualFold(label, "Ursache")) {
+			current.Reason = strings.TrimSpace(text)
+			inReason = true
+		} else if inReason {
+			// Long reasons may continue on the next lines
+			current.Reason += " " + line
+		}
+		if m := timerTaskPattern.FindStringSubmatch(current.Reason); m != nil {
+			current.TaskPath = m[1]
+		}
+	}
+
+	sort.SliceStable(timers, func(i, j int) bool { return timers[i].Expires.Before(timers[j].Expires) })
+	return timers
+}
+
+// parseTimerExpiry extracts date and time from the text following the owner of a
+// timer ("3:12:00 AM on 10/19/2026." or "läuft um 03:12:00 am 19.10.2026 ab."). The
+// date is the one directly following the time; zero if not found.
+func parseTimerExpiry(text string, loc *time.Location) time.Time {
+	ti := timerTimePattern.FindStringSubmatchIndex(text)
+	if ti == nil {
+		return time.Time{}
+	}
+	tm := timerTimePattern.FindStringSubmatch(text[ti[0]:])
+	rest := text[ti[1]:]
+	dm := timerDatePattern.FindStringSubmatch(rest)
+	if dm == nil {
+		return time.Time{}
+	}
+	// In German output "am" is followed by the date ("03:12:00 am 19.10.2026")
+	if rest = strings.TrimSpace(rest); rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+		tm[4] = ""
+	}
+
+	hour, _ := strconv.Atoi(tm[1])
+	minute, _ := strconv.Atoi(tm[2])
+	second, _ := strconv.Atoi(tm[3])
+	switch strings.ToUpper(tm[4]) {
+	case "PM":
+		if hour < 12 {
+			hour += 12
+		}
+	case "AM":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+
+	a, _ := strconv.Atoi(dm[1])
+	b, _ := strconv.Atoi(dm[3])
+	c, _ := strconv.Atoi(dm[4])
+	var year, month, day int
+	switch dm[2] {
+	case ".":
+		day, month, year = a, b, c
+	case "/":
+		month, day, year = a, b, c
+	default:
+		year, month, day = a, b, c
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, loc)
+}
+
+// queryWakeTimers runs powercfg /waketimers and parses the output
+func queryWakeTimers() ([]WakeTimer, error) {
+	output, err := runCommandWithEncoding("powercfg", "/waketimers")
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Ausführen von powercfg /waketimers: %w", err)
+	}
+	return parseWakeTimers(output, time.Local), nil
+}
+
+// findTimerForTask returns the wake timer set for a scheduled task or nil
+func findTimerForTask(timers []WakeTimer, task WakeTask) *WakeTimer {
+	for i := range timers {
+		if timers[i].TaskPath != "" && strings.EqualFold(timers[i].TaskPath, task.Path) {
+			return &timers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseWakeTimers(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*3600)
+	reboot := WakeTimer{
+		OwnerType: "SERVICE",
+		Owner:     `\Device\HarddiskVolume3\Windows\System32\svchost.exe (SystemEventsBroker)`,
+		Expires:   time.Date(2026, 10, 19, 3, 12, 0, 0, loc),
+		TaskPath:  `\Microsoft\Windows\UpdateOrchestrator\Reboot_AC`,
+	}
+	agent := WakeTimer{
+		OwnerType: "PROCESS",
+		Owner:     `\Device\HarddiskVolume3\Program Files\Contoso\Agent\1.2.3\agent.exe`,
+		Expires:   time.Date(2026, 10, 18, 12, 30, 15, 0, loc),
+		Reason:    "Contoso Agent nightly inventory",
+	}
+	winsat := WakeTimer{
+		OwnerType: "SERVICE",
+		Owner:     `\Device\HarddiskVolume3\Windows\System32\svchost.exe (SystemEventsBroker)`,
+		Expires:   time.Date(2026, 10, 20, 0, 5, 0, 0, loc),
+		TaskPath:  `\Microsoft\Windows\Maintenance\WinSAT`,
+	}
+
+	tests := []struct {
+		fixture string
+		want    []WakeTimer
+	}{
+		{"waketimers-en.txt", []WakeTimer{agent, reboot, winsat}},
+		{"waketimers-de.txt", []WakeTimer{agent, reboot}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			timers := parseWakeTimers(readFixture(t, tt.fixture), loc)
+			if len(timers) != len(tt.want) {
+				t.Fatalf("got %d timers, want %d: %+v", len(timers), len(tt.want), timers)
+			}
+			for i, want := range tt.want {
+				got := timers[i]
+				if got.OwnerType != want.OwnerType || got.Owner != want.Owner || got.TaskPath != want.TaskPath {
+					t.Errorf("timer %d = %s %q task %q, want %s %q task %q", i, got.OwnerType, got.Owner, got.TaskPath, want.OwnerType, want.Owner, want.TaskPath)
+				}
+				if !got.Expires.Equal(want.Expires) {
+					t.Errorf("timer %d expires %v, want %v", i, got.Expires, want.Expires)
+				}
+				if want.Reason != "" && got.Reason != want.Reason {
+					t.Errorf("timer %d reason %q, want %q", i, got.Reason, want.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTimerExpiry(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{" 3:12:00 AM on 10/19/2026.", time.Date(2026, 10, 19, 3, 12, 0, 0, time.UTC)},
+		{" 12:00:00 AM on 10/19/2026.", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{" 1:00:00 PM on 1/2/2026.", time.Date(2026, 1, 2, 13, 0, 0, 0, time.UTC)},
+		{" läuft um 23:59:59 am 31.12.2026 ab.", time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{" 07:00:00 on 2026-03-29.", time.Date(2026, 3, 29, 7, 0, 0, 0, time.UTC)},
+		{" 07:00:00.", time.Time{}},
+		{" unknown", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseTimerExpiry(tt.text, time.UTC); !got.Equal(tt.want) {
+			t.Errorf("parseTimerExpiry(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchEventKind is a power or device notification received by "SleepRight watch"
+type watchEventKind int
+
+const (
+	watchSuspend watchEventKind = iota
+	watchResume
+	watchPowerSettingChange
+	watchDeviceChange
+)
+
+// watchEvent is a notification with the time it was received
+type watchEvent struct {
+	Kind   watchEventKind
+	Time   time.Time
+	Detail string // e.g. the GUID of a changed power setting
+}
+
+// watchDeviceDebounce is how long device notifications have to stay quiet before the
+// wake-armed devices are re-checked; one device change causes a burst of them and
+// the device is only set up after the last one
+const watchDeviceDebounce = 5 * time.Second
+
+// WakeRecord is a wake captured right after resume
+type WakeRecord struct {
+	Time       time.Time  `json:"time"`
+	Suspended  *time.Time `json:"suspended,omitempty"` // start of the sleep, if seen
+	Source     string     `json:"source"`
+	LastWake   string     `json:"lastWake"`             // raw powercfg /lastwake output
+	NewlyArmed []string   `json:"newlyArmed,omitempty"` // devices armed while asleep
+}
+
+// watchProbe reads the wake source and wake-armed devices; the interface keeps the
+// watcher free of Windows calls
+type watchProbe interface {
+	LastWake() (string, error)
+	WakeArmed() ([]string, error)
+}
+
+// watcher reacts to power and device notifications
+type watcher struct {
+	probe  watchProbe
+	clock  clock
+	record func(WakeRecord) error
+	logf   func(format string, args ...interface{})
+	emit   func(Event) // writes events to the event log
+	// devices the profile allows to wake the computer
+	allowed wakeDeviceSet
+
+	armed        map[string]bool // wake-armed devices at the last check, nil before the first
+	suspendedAt  time.Time
+	resumed      bool             // a resume was handled since the last suspend
+	deviceSettle <-chan time.Time // fires watchDeviceDebounce after the last device notification
+}
+
+// run handles the notifications until events is closed and re-checks the devices
+// once device notifications have settled
+func (w *watcher) run(events <-chan watchEvent) {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case <-w.deviceSettle:
+			w.deviceSettle = nil
+			w.checkDevices()
+		}
+	}
+}
+
+// handle processes one notification. Windows sends PBT_APMRESUMEAUTOMATIC and,
+// if a user is present, PBT_APMRESUMESUSPEND; only the first resume after a
+// suspend is recorded.
+func (w *watcher) handle(event watchEvent) {
+	switch event.Kind {
+	case watchSuspend:
+		w.suspendedAt, w.resumed = event.Time, false
+		w.logf("suspend")
+	case watchResume:
+		if w.resumed {
+			return
+		}
+		w.resumed = true
+		// powercfg /lastwake is overwritten by the next wake; read it before anything else
+		output, err := w.probe.LastWake()
+		if err != nil {
+			w.logf("resume: failed to read the wake source: %v", err)
+		}
+		record := WakeRecord{Time: event.Time, Source: summarizeLastWake(output), LastWake: strings.TrimSpace(output)}
+		if !w.suspendedAt.IsZero() {
+			suspended := w.suspendedAt
+			record.Suspended = &suspended
+		}
+		record.NewlyArmed = w.checkDevices()
+		w.logf("resume: woken by %s", record.Source)
+		if !isExpectedWakeSource(record.Source) {
+			w.emit(unexpectedWakeEvent(record))
+		}
+		if err := w.record(record); err != nil {
+			w.logf("failed to record wake: %v", err)
+		}
+	case watchPowerSettingChange:
+		w.logf("power setting changed: %s", event.Detail)
+	case watchDeviceChange:
+		// Every notification restarts the wait, the check follows the last one
+		w.deviceSettle = w.clock.After(watchDeviceDebounce)
+	}
+}
+
+// checkDevices compares the wake-armed devices with the previous check and reports
+// devices that were armed in the meantime and are not allowed by the profile
+func (w *watcher) checkDevices() []string {
+	devices, err := w.probe.WakeArmed()
+	if err != nil {
+		w.logf("failed to query wake-armed devices: %v", err)
+		return nil
+	}
+
+	var newlyArmed []string
+	current := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		current[device] = true
+		if w.armed != nil && !w.armed[device] {
+			newlyArmed = append(newlyArmed, device)
+			if w.allowed.Allows(device) {
+				w.logf("device armed for wake: %s", device)
+			} else {
+				w.logf("device armed for wake, not allowed by the profile: %s (run \"SleepRight -configure\" to disarm it)", device)
+			}
+		}
+	}
+	w.armed = current
+	return newlyArmed
+}
+
+// lastWakeSourceLabels are the labels of the wake source name in powercfg /lastwake
+var lastWakeSourceLabels = []string{"Friendly Name", "Anzeigename", "Description", "Beschreibung", "Instance Path", "Instanzpfad"}
+
+// summarizeLastWake returns the wake source named in powercfg /lastwake output
+func summarizeLastWake(output string) string {
+	lines := strings.Split(decodeCP1252(output), "\n")
+	for _, label := range lastWakeSourceLabels {
+		for _, line := range lines {
+			key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+			if found && strings.EqualFold(strings.TrimSpace(key), label) && strings.TrimSpace(value) != "" {
+				return strings.TrimSpace(value)
+			}
+		}
+	}
+	return "unknown"
+}
+
+// wakeHistoryPath returns the file the watcher appends wake records to
+func wakeHistoryPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wake-history.jsonl"), nil
+}
+
+// appendWakeRecord appends a wake record as JSON line to the wake history
+func appendWakeRecord(record WakeRecord) error {
+	path, err := wakeHistoryPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode wake record: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// powercfgProbe reads the wake source and devices with powercfg
+type powercfgProbe struct{}
+
+func (powercfgProbe) LastWake() (string, error) {
+	return runCommandWithEncoding("powercfg", "/lastwake")
+}
+
+func (powercfgProbe) WakeArmed() ([]string, error) {
+	output, err := runCommandWithEncoding("powercfg", "/devicequery", "wake_armed")
+	if err != nil {
+		return nil, err
+	}
+	var devices []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.EqualFold(line, "NONE") && !strings.EqualFold(line, "KEINE") {
+			devices = append(devices, line)
+		}
+	}
+	return devices, nil
+}
+
+func setupWatchCommand(fs *flag.FlagSet) {
+	fs.StringVar(&profilePath, "profile", "", "Profile whose wake devices are allowed (default: built-in profile)")
+}
+
+// runWatchCommand records wakes and re-checks wake devices on power notifications
+func runWatchCommand(args []string) int {
+	profile, err := loadProfile(profilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := runWatch(profile.WakeDevices()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
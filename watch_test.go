@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProbe returns a fixed wake source and the wake-armed devices set by the test;
+// every WakeArmed call is signalled on checks if set
+type fakeProbe struct {
+	mu       sync.Mutex
+	lastWake string
+	armed    []string
+	queried  int
+	checks   chan struct{}
+}
+
+func (p *fakeProbe) LastWake() (string, error) {
+	return p.lastWake, nil
+}
+
+func (p *fakeProbe) WakeArmed() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queried++
+	if p.checks != nil {
+		p.checks <- struct{}{}
+	}
+	return append([]string(nil), p.armed...), nil
+}
+
+func (p *fakeProbe) setArmed(devices ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.armed = devices
+}
+
+func (p *fakeProbe) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queried
+}
+
+func newTestWatcher(probe *fakeProbe, c clock) (*watcher, *[]WakeRecord, *[]uint32) {
+	var records []WakeRecord
+	var events []uint32
+	w := &watcher{
+		probe:  probe,
+		clock:  c,
+		record: func(record WakeRecord) error { records = append(records, record); return nil },
+		logf:   func(string, ...interface{}) {},
+		emit:   func(event Event) { events = append(events, event.ID) },
+		// The devices allowed by the default profile
+		allowed: wakeDeviceSet{Keyboard: true, Network: true},
+	}
+	return w, &records, &events
+}
+
+func TestWatcherResume(t *testing.T) {
+	probe := &fakeProbe{
+		lastWake: "Wake History Count - 1\r\nWake Source Count - 1\r\nWake Source [0]\r\n  Type: Device\r\n  Instance Path: PCI\\VEN_8086\r\n  Friendly Name: Intel(R) Ethernet Connection\r\n",
+		armed:    []string{"HID Keyboard Device"},
+	}
+	w, records, events := newTestWatcher(probe, newFakeClock(time.Time{}))
+	w.checkDevices()
+
+	suspended := time.Date(2026, 3, 4, 23, 0, 0, 0, time.UTC)
+	resumed := time.Date(2026, 3, 5, 3, 12, 0, 0, time.UTC)
+	probe.setArmed("HID Keyboard Device", "Intel(R) Ethernet Connection")
+	w.handle(watchEvent{Kind: watchSuspend, Time: suspended})
+	w.handle(watchEvent{Kind: watchResume, Time: resumed})
+	// Windows sends a second resume if a user is present
+	w.handle(watchEvent{Kind: watchResume, Time: resumed.Add(time.Second)})
+
+	if len(*records) != 1 {
+		t.Fatalf("recorded %d wakes, want 1", len(*records))
+	}
+	record := (*records)[0]
+	if record.Source != "Intel(R) Ethernet Connection" {
+		t.Errorf("Source = %q", record.Source)
+	}
+	if record.Suspended == nil || !record.Suspended.Equal(suspended) {
+		t.Errorf("Suspended = %v, want %s", record.Suspended, suspended)
+	}
+	if strings.Join(record.NewlyArmed, ",") != "Intel(R) Ethernet Connection" {
+		t.Errorf("NewlyArmed = %v", record.NewlyArmed)
+	}
+	if len(*events) != 1 || (*events)[0] != eventUnexpectedWake {
+		t.Errorf("events = %v, want the unexpected wake", *events)
+	}
+}
+
+func TestWatcherResumeWithoutSuspend(t *testing.T) {
+	probe := &fakeProbe{lastWake: "Friendly Name: HID Keyboard Device\r\n"}
+	w, records, events := newTestWatcher(probe, newFakeClock(time.Time{}))
+	// The watcher was started while the computer was already running
+	w.handle(watchEvent{Kind: watchResume, Time: time.Date(2026, 3, 5, 7, 0, 0, 0, time.UTC)})
+
+	if len(*records) != 1 {
+		t.Fatalf("recorded %d wakes, want 1", len(*records))
+	}
+	if (*records)[0].Suspended != nil {
+		t.Errorf("Suspended = %v, want nil", (*records)[0].Suspended)
+	}
+	data, err := json.Marshal((*records)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "suspended") {
+		t.Errorf("unknown suspend time is written: %s", data)
+	}
+	if len(*events) != 0 {
+		t.Errorf("events = %v, want none for a keyboard wake", *events)
+	}
+}
+
+func TestWatcherAllowedDevices(t *testing.T) {
+	probe := &fakeProbe{}
+	w, _, _ := newTestWatcher(probe, newFakeClock(time.Time{}))
+	var logged []string
+	w.logf = func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) }
+	// The profile only allows the network adapter ("wake-devices: network")
+	w.allowed = wakeDeviceSet{Network: true}
+	w.checkDevices()
+
+	probe.setArmed("HID Keyboard Device", "Intel(R) Ethernet Connection")
+	if newlyArmed := w.checkDevices(); len(newlyArmed) != 2 {
+		t.Fatalf("newly armed = %v, want both devices", newlyArmed)
+	}
+	want := []string{
+		`device armed for wake, not allowed by the profile: HID Keyboard Device (run "SleepRight -configure" to disarm it)`,
+		"device armed for wake: Intel(R) Ethernet Connection",
+	}
+	if strings.Join(logged, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged %q, want %q", logged, want)
+	}
+}
+
+func TestWatcherDeviceDebounce(t *testing.T) {
+	c := newFakeClock(time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC))
+	probe := &fakeProbe{}
+	w, _, _ := newTestWatcher(probe, c)
+	w.checkDevices()
+	probe.checks = make(chan struct{}, 1)
+
+	events, done := make(chan watchEvent), make(chan struct{})
+	go func() {
+		w.run(events)
+		close(done)
+	}()
+
+	wait := func() fakeTimer {
+		t.Helper()
+		select {
+		case timer := <-c.timers:
+			return timer
+		case <-time.After(5 * time.Second):
+			t.Fatal("watcher did not wait for the device notifications to settle")
+			return fakeTimer{}
+		}
+	}
+
+	// A burst of notifications restarts the wait every time
+	var timers []fakeTimer
+	for i := 0; i < 3; i++ {
+		events <- watchEvent{Kind: watchDeviceChange, Time: c.Now()}
+		timers = append(timers, wait())
+		c.Sleep(time.Second)
+	}
+	for _, timer := range timers {
+		if timer.d != watchDeviceDebounce {
+			t.Errorf("waits %s, want %s", timer.d, watchDeviceDebounce)
+		}
+	}
+
+	// The wait started by an earlier notification does not trigger a check, the one
+	// after the last notification does
+	timers[0].ch <- c.Now()
+	probe.setArmed("Intel(R) Ethernet Connection")
+	timers[2].ch <- c.Now()
+	select {
+	case <-probe.checks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("devices not re-checked after the notifications settled")
+	}
+
+	close(events)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after the events were closed")
+	}
+	// The initial snapshot and one re-check
+	if queried := probe.count(); queried != 2 {
+		t.Errorf("queried the devices %d times, want 2", queried)
+	}
+	if !w.armed["Intel(R) Ethernet Connection"] {
+		t.Errorf("armed = %v, the re-check was not applied", w.armed)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Window messages and power broadcast/device change events
+const (
+	wmPowerBroadcast = 0x0218
+	wmDeviceChange   = 0x0219
+
+	pbtAPMSuspend           = 0x0004
+	pbtAPMResumeSuspend     = 0x0007
+	pbtAPMResumeAutomatic   = 0x0012
+	pbtPowerSettingChange   = 0x8013
+	dbtDevNodesChanged      = 0x0007
+	dbtDeviceArrival        = 0x8000
+	dbtDeviceRemoveComplete = 0x8004
+
+	deviceNotifyWindowHandle = 0
+)
+
+var (
+	user32                               = windows.NewLazySystemDLL("user32.dll")
+	procRegisterClassExW                 = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW                  = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW                   = user32.NewProc("DefWindowProcW")
+	procGetMessageW                      = user32.NewProc("GetMessageW")
+	procTranslateMessage                 = user32.NewProc("TranslateMessage")
+	procDispatchMessageW                 = user32.NewProc("DispatchMessageW")
+	procRegisterPowerSettingNotification = user32.NewProc("RegisterPowerSettingNotification")
+)
+
+// watchedPowerSettings are the power settings whose changes are reported
+var watchedPowerSettings = map[windows.GUID]string{
+	windows.GUID{Data1: 0x5d3e9a59, Data2: 0xe9d5, Data3: 0x4b00, Data4: [8]byte{0xa6, 0xbd, 0xff, 0x34, 0xff, 0x51, 0x65, 0x48}}: "power source",
+	windows.GUID{Data1: 0x6fe69556, Data2: 0x704a, Data3: 0x47a0, Data4: [8]byte{0x8f, 0x24, 0xc2, 0x8d, 0x93, 0x6f, 0xda, 0x47}}: "display state",
+	windows.GUID{Data1: 0x245d8541, Data2: 0x3943, Data3: 0x4422, Data4: [8]byte{0xb0, 0x25, 0x13, 0xa7, 0x84, 0xf6, 0x79, 0xb7}}: "power scheme",
+}
+
+// wndClassEx is WNDCLASSEXW
+type wndClassEx struct {
+	Size       uint32
+	Style      uint32
+	WndProc    uintptr
+	ClsExtra   int32
+	WndExtra   int32
+	Instance   windows.Handle
+	Icon       windows.Handle
+	Cursor     windows.Handle
+	Background windows.Handle
+	MenuName   *uint16
+	ClassName  *uint16
+	IconSm     windows.Handle
+}
+
+// windowMessage is MSG
+type windowMessage struct {
+	Hwnd    windows.HWND
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+	Private uint32
+}
+
+// powerBroadcastSetting is the header of POWERBROADCAST_SETTING
+type powerBroadcastSetting struct {
+	PowerSetting windows.GUID
+	DataLength   uint32
+}
+
+// watchEvents receives the notifications from the window procedure; the watcher
+// runs in its own goroutine so the window procedure answers immediately
+var watchEvents = make(chan watchEvent, 64)
+
+// watchWindowProc translates power and device messages into watch events
+func watchWindowProc(hwnd windows.HWND, message uint32, wParam, lParam uintptr) uintptr {
+	event := watchEvent{Time: time.Now()}
+	switch {
+	case message == wmPowerBroadcast && wParam == pbtAPMSuspend:
+		event.Kind = watchSuspend
+	case message == wmPowerBroadcast && (wParam == pbtAPMResumeAutomatic || wParam == pbtAPMResumeSuspend):
+		event.Kind = watchResume
+	case message == wmPowerBroadcast && wParam == pbtPowerSettingChange && lParam != 0:
+		// lParam points to a POWERBROADCAST_SETTING owned by the system
+		setting := (*powerBroadcastSetting)(unsafe.Add(nil, lParam))
+		event.Kind, event.Detail = watchPowerSettingChange, watchedPowerSettings[setting.PowerSetting]
+		if event.Detail == "" {
+			event.Detail = setting.PowerSetting.String()
+		}
+	case message == wmDeviceChange && (wParam == dbtDevNodesChanged || wParam == dbtDeviceArrival || wParam == dbtDeviceRemoveComplete):
+		event.Kind = watchDeviceChange
+	default:
+		ret, _, _ := procDefWindowProcW.Call(uintptr(hwnd), uintptr(message), wParam, lParam)
+		return ret
+	}
+	select {
+	case watchEvents <- event:
+	default:
+		// The watcher is busy; dropping a device notification burst is harmless
+	}
+	if message == wmPowerBroadcast {
+		return 1 // TRUE
+	}
+	return 0
+}
+
+// createWatchWindow creates the hidden window receiving the notifications. A
+// message-only window would not receive WM_POWERBROADCAST and WM_DEVICECHANGE
+// broadcasts, so it is a top-level window that is never shown.
+func createWatchWindow() (windows.HWND, error) {
+	var instance windows.Handle
+	if err := windows.GetModuleHandleEx(0, nil, &instance); err != nil {
+		return 0, fmt.Errorf("failed to get module handle: %w", err)
+	}
+	className, _ := windows.UTF16PtrFromString("SleepRightWatch")
+	class := wndClassEx{
+		WndProc:   windows.NewCallback(watchWindowProc),
+		Instance:  instance,
+		ClassName: className,
+	}
+	class.Size = uint32(unsafe.Sizeof(class))
+	if atom, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&class))); atom == 0 {
+		return 0, fmt.Errorf("failed to register window class: %w", err)
+	}
+	hwnd, _, err := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(className)), uintptr(unsafe.Pointer(className)),
+		0, 0, 0, 0, 0, 0, 0, uintptr(instance), 0)
+	if hwnd == 0 {
+		return 0, fmt.Errorf("failed to create window: %w", err)
+	}
+
+	for guid := range watchedPowerSettings {
+		guid := guid
+		if handle, _, err := procRegisterPowerSettingNotification.Call(hwnd, uintptr(unsafe.Pointer(&guid)), deviceNotifyWindowHandle); handle == 0 && verboseFlag {
+			fmt.Printf("Warning: failed to register power setting notification %s: %v\n", guid.String(), err)
+		}
+	}
+	return windows.HWND(hwnd), nil
+}
+
+// runWatch creates the notification window and processes its messages until the
+// process is terminated
+func runWatch(allowed wakeDeviceSet) error {
+	// The window and its message loop must stay on one thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if _, err := createWatchWindow(); err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	w := &watcher{probe: powercfgProbe{}, clock: systemClock{}, record: appendWakeRecord, logf: logger.Printf, emit: writeEvent, allowed: allowed}
+	// Take the initial snapshot of wake-armed devices
+	w.checkDevices()
+	go w.run(watchEvents)
+	logger.Printf("watching power and device notifications (Ctrl+C to stop)")
+
+	var message windowMessage
+	for {
+		ret, _, err := procGetMessageW.Call(uintptr(unsafe.Pointer(&message)), 0, 0, 0)
+		switch int32(ret) {
+		case -1:
+			return fmt.Errorf("failed to get window message: %w", err)
+		case 0:
+			return nil
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&message)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&message)))
+	}
+}